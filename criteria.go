@@ -0,0 +1,212 @@
+package goquery
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Criterion renders a filter condition for a where clause.  Values are appended
+// to params and rendered as bind parameters in the dialect bind format.
+type Criterion func(dialect DbDialect, params []interface{}) (string, []interface{}, error)
+
+// Order is a column in an order by clause.
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+func Asc(column string) Order {
+	return Order{Column: column}
+}
+
+func Desc(column string) Order {
+	return Order{Column: column, Desc: true}
+}
+
+// Eq matches rows where column equals value.  A nil value, or a nil pointer, matches null columns.
+func Eq(column string, value interface{}) Criterion {
+	if isNull(value) {
+		return IsNull(column)
+	}
+	return compare(column, "=", value)
+}
+
+// Ne matches rows where column does not equal value.  A nil value, or a nil pointer, matches
+// non null columns.
+func Ne(column string, value interface{}) Criterion {
+	if isNull(value) {
+		return IsNotNull(column)
+	}
+	return compare(column, "<>", value)
+}
+
+func Gt(column string, value interface{}) Criterion {
+	return compare(column, ">", value)
+}
+
+func Gte(column string, value interface{}) Criterion {
+	return compare(column, ">=", value)
+}
+
+func Lt(column string, value interface{}) Criterion {
+	return compare(column, "<", value)
+}
+
+func Lte(column string, value interface{}) Criterion {
+	return compare(column, "<=", value)
+}
+
+func Like(column string, pattern string) Criterion {
+	return compare(column, "like", pattern)
+}
+
+// In matches rows where column is one of the elements of values, which must be a slice.
+func In(column string, values interface{}) Criterion {
+	return func(dialect DbDialect, params []interface{}) (string, []interface{}, error) {
+		if err := validColumn(column); err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s in (%s)", column, nextBind(dialect, params)), append(params, values), nil
+	}
+}
+
+func IsNull(column string) Criterion {
+	return nullCheck(column, "is null")
+}
+
+func IsNotNull(column string) Criterion {
+	return nullCheck(column, "is not null")
+}
+
+// And matches rows that match all of the criteria.  And with no criteria matches every row.
+func And(criteria ...Criterion) Criterion {
+	return join("and", "1=1", criteria)
+}
+
+// Or matches rows that match any of the criteria.  Or with no criteria matches no rows.
+func Or(criteria ...Criterion) Criterion {
+	return join("or", "1=0", criteria)
+}
+
+func Not(criterion Criterion) Criterion {
+	return func(dialect DbDialect, params []interface{}) (string, []interface{}, error) {
+		sql, params, err := criterion(dialect, params)
+		if err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("not (%s)", sql), params, nil
+	}
+}
+
+func compare(column string, op string, value interface{}) Criterion {
+	return func(dialect DbDialect, params []interface{}) (string, []interface{}, error) {
+		if err := validColumn(column); err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s %s %s", column, op, nextBind(dialect, params)), append(params, value), nil
+	}
+}
+
+func nullCheck(column string, check string) Criterion {
+	return func(dialect DbDialect, params []interface{}) (string, []interface{}, error) {
+		if err := validColumn(column); err != nil {
+			return "", nil, err
+		}
+		return fmt.Sprintf("%s %s", column, check), params, nil
+	}
+}
+
+// join combines criteria with op.  empty is the condition used when there are no criteria.
+func join(op string, empty string, criteria []Criterion) Criterion {
+	return func(dialect DbDialect, params []interface{}) (string, []interface{}, error) {
+		clauses := make([]string, len(criteria))
+		for i, criterion := range criteria {
+			var err error
+			clauses[i], params, err = criterion(dialect, params)
+			if err != nil {
+				return "", nil, err
+			}
+			clauses[i] = "(" + clauses[i] + ")"
+		}
+		if len(clauses) == 0 {
+			return empty, params, nil
+		}
+		return strings.Join(clauses, " "+op+" "), params, nil
+	}
+}
+
+// isNull reports whether value is nil or a nil pointer.
+func isNull(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
+
+// nextBind returns the bind parameter for the next value appended to params.
+func nextBind(dialect DbDialect, params []interface{}) string {
+	n := len(params)
+	return dialect.Bind(fmt.Sprintf("p%d", n+1), n)
+}
+
+// validIdentifier checks that name is a plain or dot qualified identifier so it can
+// be written into a statement.
+func validIdentifier(name string) error {
+	for _, part := range strings.Split(name, ".") {
+		if part == "" || part != paramName(part) {
+			return fmt.Errorf("invalid identifier: %q", name)
+		}
+	}
+	return nil
+}
+
+// validColumn checks that column is a plain identifier so it can be written into a
+// statement.  Criteria apply to the result columns of the wrapped statement so table
+// qualified names such as t.id are rejected.
+func validColumn(column string) error {
+	if column == "" || column != paramName(column) {
+		return fmt.Errorf("invalid column name: %q.  criteria columns must be unqualified result column names", column)
+	}
+	return nil
+}
+
+// applyCriteria filters, orders and limits stmt by the query criteria.  The statement
+// is wrapped in a subquery so criteria apply to its result columns and can be combined
+// with statements that already have where, order by or suffix clauses.
+func applyCriteria(stmt string, params []interface{}, qi QueryInput, dialect DbDialect) (string, []interface{}, error) {
+	if len(qi.Criteria) == 0 && len(qi.Order) == 0 && qi.Limit <= 0 && qi.Offset <= 0 {
+		return stmt, params, nil
+	}
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("select * from (%s) q", stmt))
+	if len(qi.Criteria) > 0 {
+		where, wparams, err := And(qi.Criteria...)(dialect, params)
+		if err != nil {
+			return "", nil, err
+		}
+		params = wparams
+		builder.WriteString(" where ")
+		builder.WriteString(where)
+	}
+	if len(qi.Order) > 0 {
+		orders := make([]string, len(qi.Order))
+		for i, order := range qi.Order {
+			if err := validColumn(order.Column); err != nil {
+				return "", nil, err
+			}
+			orders[i] = order.Column
+			if order.Desc {
+				orders[i] += " desc"
+			}
+		}
+		builder.WriteString(" order by ")
+		builder.WriteString(strings.Join(orders, ","))
+	}
+	if qi.Limit > 0 || qi.Offset > 0 {
+		builder.WriteString(" ")
+		builder.WriteString(dialect.LimitOffset(qi.Limit, qi.Offset))
+	}
+	return builder.String(), params, nil
+}
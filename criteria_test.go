@@ -0,0 +1,82 @@
+package goquery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyCriteria(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  DbDialect
+		params   []interface{}
+		qi       QueryInput
+		want     string
+		wantArgs []interface{}
+	}{
+		{
+			name:    "pg",
+			dialect: pgDialect,
+			params:  []interface{}{"x"},
+			qi: QueryInput{
+				Criteria: []Criterion{Eq("id", 1), Or(Gt("ts", 2), IsNull("ts")), In("code", []string{"a"})},
+				Order:    []Order{Desc("ts"), Asc("id")},
+				Limit:    10,
+				Offset:   20,
+			},
+			want:     "select * from (select * from t where name=$1) q where (id = $2) and ((ts > $3) or (ts is null)) and (code in ($4)) order by ts desc,id limit 10 offset 20",
+			wantArgs: []interface{}{"x", 1, 2, []string{"a"}},
+		},
+		{
+			name:    "oracle",
+			dialect: oracleDialect,
+			qi: QueryInput{
+				Criteria: []Criterion{Eq("name", nil), Not(Like("location", "P%"))},
+				Limit:    5,
+			},
+			want:     "select * from (select * from t where name=$1) q where (name is null) and (not (location like :p1)) offset 0 rows fetch next 5 rows only",
+			wantArgs: []interface{}{"P%"},
+		},
+		{
+			name:    "empty and nil pointer",
+			dialect: pgDialect,
+			qi: QueryInput{
+				Criteria: []Criterion{Or(), And(), Ne("location", (*string)(nil))},
+			},
+			want: "select * from (select * from t where name=$1) q where (1=0) and (1=1) and (location is not null)",
+		},
+		{
+			name:     "none",
+			dialect:  pgDialect,
+			params:   []interface{}{"x"},
+			want:     "select * from t where name=$1",
+			wantArgs: []interface{}{"x"},
+		},
+	}
+	for _, test := range tests {
+		got, args, err := applyCriteria("select * from t where name=$1", test.params, test.qi, test.dialect)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: Got %s want %s", test.name, got, test.want)
+		}
+		if !reflect.DeepEqual(args, test.wantArgs) {
+			t.Errorf("%s: Got args %v want %v", test.name, args, test.wantArgs)
+		}
+	}
+
+	invalid := []QueryInput{
+		{Criteria: []Criterion{Eq("id; drop table t", 1)}},
+		{Criteria: []Criterion{Or(IsNull("1id"))}},
+		{Order: []Order{Asc("id desc")}},
+		{Order: []Order{Asc("q.")}},
+		{Order: []Order{Asc("t.id")}},
+		{Criteria: []Criterion{Eq("t.id", 1)}},
+	}
+	for _, qi := range invalid {
+		if _, _, err := applyCriteria("select * from t", nil, qi, pgDialect); err == nil {
+			t.Errorf("Expected an invalid column error for %v", qi)
+		}
+	}
+}
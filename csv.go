@@ -1,81 +1,379 @@
 package goquery
 
 import (
+	"bufio"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"reflect"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgtype"
 	"github.com/stoewer/go-strcase"
 )
 
+type CsvQuotePolicy uint8
+
+const (
+	//quote fields only when they contain a delimiter, quote or line break
+	CsvQuoteMinimal CsvQuotePolicy = iota
+	CsvQuoteAlways
+	//never quote or escape fields
+	CsvQuoteNever
+)
+
+// CsvDialect controls the format of csv output.  The zero value writes
+// comma delimited, minimally quoted records terminated by "\n".
+type CsvDialect struct {
+	Delimiter      rune
+	Quote          CsvQuotePolicy
+	LineTerminator string
+	//header names keyed by column name, used in place of the column name
+	HeaderAliases map[string]string
+	//write a UTF-8 byte order mark before the first record
+	BOM bool
+}
+
+// RowsToCSV converts rows to a csv string with a header row.
+// @deprecated: use WriteCSV to stream rows to a writer.
 func RowsToCSV(rows Rows, toCamelCase bool, dateFormat string) (string, error) {
+	var builder strings.Builder
+	options := OutputOptions{
+		ToCamelCase:    toCamelCase,
+		DateFormat:     dateFormat,
+		CsvPrintHeader: true,
+	}
+	err := WriteCSV(&builder, rows, options)
+	return builder.String(), err
+}
+
+// WriteCSV streams rows to writer as csv, one record per row, formatted with
+// options.CsvDialect.  Null values are written as options.CsvNullToken, which
+// defaults to an empty field.
+func WriteCSV(writer io.Writer, rows Rows, options OutputOptions) error {
 	columns, err := rows.Columns()
 	if err != nil {
-		return "", fmt.Errorf("column error: %v", err)
+		return fmt.Errorf("column error: %v", err)
 	}
 
 	ct, err := rows.ColumnTypes()
 	if err != nil {
-		return "", fmt.Errorf("column type error: %v", err)
+		return fmt.Errorf("column type error: %v", err)
 	}
 
+	//scan into pointers to each column type so null values can be detected
 	types := make([]reflect.Type, len(ct))
 	for i, tp := range ct {
-		types[i] = tp
+		types[i] = reflect.PtrTo(tp)
 	}
 
-	values := make([]interface{}, len(ct))
-	var builder strings.Builder
+	csvWriter, err := newCsvWriter(writer, options.CsvDialect)
+	if err != nil {
+		return err
+	}
 
-	for i, col := range columns {
-		if i > 0 {
-			builder.WriteString(",")
+	if options.CsvPrintHeader {
+		header := make([]string, len(columns))
+		for i, col := range columns {
+			if alias, ok := options.CsvDialect.HeaderAliases[col]; ok {
+				col = alias
+			} else if options.ToCamelCase {
+				col = strcase.LowerCamelCase(col)
+			}
+			header[i] = col
 		}
-		if toCamelCase {
-			col = strcase.LowerCamelCase(col)
+		err = csvWriter.Write(header)
+		if err != nil {
+			return err
 		}
-		builder.WriteString(`"` + col + `"`)
 	}
-	builder.WriteString("\n")
 
+	values := make([]interface{}, len(ct))
+	record := make([]string, len(ct))
 	for rows.Next() {
 		for i := range values {
 			values[i] = reflect.New(types[i]).Interface()
 		}
 		err = rows.Scan(values...)
 		if err != nil {
-			return "", fmt.Errorf("failed to scan values: %v", err)
+			return fmt.Errorf("failed to scan values: %v", err)
 		}
 		for i, v := range values {
-			if i > 0 {
-				builder.WriteString(",")
+			record[i], err = csvValue(v, options)
+			if err != nil {
+				return err
+			}
+		}
+		err = csvWriter.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	return csvWriter.Flush()
+}
+
+type csvWriter struct {
+	w       *bufio.Writer
+	dialect CsvDialect
+	bom     bool
+}
+
+func newCsvWriter(w io.Writer, dialect CsvDialect) (*csvWriter, error) {
+	if dialect.Delimiter == 0 {
+		dialect.Delimiter = ','
+	}
+	if dialect.LineTerminator == "" {
+		dialect.LineTerminator = "\n"
+	}
+	if dialect.Delimiter == '"' || dialect.Delimiter == '\r' || dialect.Delimiter == '\n' ||
+		!utf8.ValidRune(dialect.Delimiter) || dialect.Delimiter == utf8.RuneError {
+		return nil, fmt.Errorf("invalid csv delimiter: %q", dialect.Delimiter)
+	}
+	return &csvWriter{bufio.NewWriter(w), dialect, dialect.BOM}, nil
+}
+
+func (cw *csvWriter) Write(record []string) error {
+	if cw.bom {
+		cw.bom = false
+		if _, err := cw.w.WriteString("\uFEFF"); err != nil {
+			return err
+		}
+	}
+	for i, field := range record {
+		if i > 0 {
+			if _, err := cw.w.WriteRune(cw.dialect.Delimiter); err != nil {
+				return err
 			}
-			var valstring string
-			switch v.(type) {
-			case string, *string:
-				valstring = fmt.Sprintf("\"%s\"", reflect.ValueOf(v).Elem().String())
-			case int, *int, int32, *int32, int64, *int64:
-				valstring = fmt.Sprintf("%d", reflect.ValueOf(v).Elem().Int())
-			case float32, *float32, float64, *float64:
-				valstring = fmt.Sprintf("%f", reflect.ValueOf(v).Elem().Float())
-			case time.Time, *time.Time:
-				if dateFormat != "" {
-					valstring = reflect.ValueOf(v).Elem().Interface().(time.Time).Format(dateFormat)
-				} else {
-					valstring = reflect.ValueOf(v).Elem().Interface().(time.Time).String()
-				}
-
-			case uuid.UUID:
-				valstring = v.(*uuid.UUID).String()
-			default:
-				return "", fmt.Errorf("unsupported csv conversion type: %v", reflect.ValueOf(v).Elem().Type())
+		}
+		if err := cw.writeField(field); err != nil {
+			return err
+		}
+	}
+	_, err := cw.w.WriteString(cw.dialect.LineTerminator)
+	return err
+}
+
+func (cw *csvWriter) writeField(field string) error {
+	if !cw.needsQuotes(field) {
+		_, err := cw.w.WriteString(field)
+		return err
+	}
+	if err := cw.w.WriteByte('"'); err != nil {
+		return err
+	}
+	if _, err := cw.w.WriteString(strings.ReplaceAll(field, `"`, `""`)); err != nil {
+		return err
+	}
+	return cw.w.WriteByte('"')
+}
+
+// needsQuotes follows the encoding/csv rules for minimal quoting
+func (cw *csvWriter) needsQuotes(field string) bool {
+	switch cw.dialect.Quote {
+	case CsvQuoteAlways:
+		return true
+	case CsvQuoteNever:
+		return false
+	}
+	if field == "" {
+		return false
+	}
+	if field == `\.` {
+		return true
+	}
+	if strings.ContainsRune(field, cw.dialect.Delimiter) || strings.ContainsAny(field, "\"\r\n") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(field)
+	return unicode.IsSpace(r)
+}
+
+func (cw *csvWriter) Flush() error {
+	return cw.w.Flush()
+}
+
+// csvValue formats a scanned column value.  v is a pointer to a pointer
+// to the column type and is nil when the column value is null.
+func csvValue(v interface{}, options OutputOptions) (string, error) {
+	ptr := reflect.ValueOf(v).Elem()
+	if ptr.IsNil() {
+		return options.CsvNullToken, nil
+	}
+	return csvFormat(ptr.Elem().Interface(), options)
+}
+
+func csvFormat(val interface{}, options OutputOptions) (string, error) {
+	switch v := val.(type) {
+	case nil:
+		return options.CsvNullToken, nil
+	case string:
+		return v, nil
+	case []byte:
+		return `\x` + hex.EncodeToString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		if options.DateFormat != "" {
+			return v.Format(options.DateFormat), nil
+		}
+		return v.String(), nil
+	case uuid.UUID:
+		return v.String(), nil
+	case *PgxNumeric:
+		if v == nil {
+			return options.CsvNullToken, nil
+		}
+		return csvFormat(v.Numeric, options)
+	case PgxNumeric:
+		return csvFormat(v.Numeric, options)
+	case *pgtype.Numeric:
+		if v == nil {
+			return options.CsvNullToken, nil
+		}
+		return csvFormat(*v, options)
+	case pgtype.Numeric:
+		//the driver value of a numeric is <int>e<exp> text
+		if text, ok := numericText(v); ok {
+			return text, nil
+		}
+		return options.CsvNullToken, nil
+	case driver.Valuer:
+		//sql.Null* types and other driver values
+		dv, err := v.Value()
+		if err != nil {
+			return "", fmt.Errorf("csv conversion error: %v", err)
+		}
+		return csvFormat(dv, options)
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return options.CsvNullToken, nil
+		}
+		return csvFormat(rv.Elem().Interface(), options)
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		//arrays and composite values are written as json
+		b, err := json.Marshal(val)
+		if err != nil {
+			return "", fmt.Errorf("unsupported csv conversion type %v: %v", rv.Type(), err)
+		}
+		return string(b), nil
+	default:
+		return fmt.Sprint(val), nil
+	}
+}
+
+var csvTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// parseCsvValue converts a csv field to the type of field and sets it.
+func parseCsvValue(field reflect.Value, val string, nullToken string, dateFormat string) error {
+	if val == nullToken {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	if field.Kind() == reflect.Pointer {
+		ptr := reflect.New(field.Type().Elem())
+		err := parseCsvValue(ptr.Elem(), val, nullToken, dateFormat)
+		if err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+	switch field.Interface().(type) {
+	case time.Time:
+		t, err := parseCsvTime(val, dateFormat)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	case []byte:
+		if strings.HasPrefix(val, `\x`) {
+			b, err := hex.DecodeString(val[2:])
+			if err != nil {
+				return err
 			}
+			field.SetBytes(b)
+		} else {
+			field.SetBytes([]byte(val))
+		}
+		return nil
+	}
+	//sql.Null* types, uuid.UUID and other sql scanners
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(val)
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(val)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(val)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(val, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(val, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported csv conversion type: %v", field.Type())
+	}
+	return nil
+}
 
-			builder.WriteString(fmt.Sprintf("%v", valstring))
+func parseCsvTime(val string, dateFormat string) (time.Time, error) {
+	if dateFormat != "" {
+		return time.Parse(dateFormat, val)
+	}
+	for _, layout := range csvTimeLayouts {
+		if t, err := time.Parse(layout, val); err == nil {
+			return t, nil
 		}
-		builder.WriteString("\n")
 	}
-	return builder.String(), nil
+	return time.Time{}, fmt.Errorf("unable to parse %s as a time", val)
 }
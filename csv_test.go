@@ -0,0 +1,227 @@
+package goquery
+
+import (
+	"database/sql"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgtype"
+)
+
+// memRows is an in memory Rows implementation used to test the output writers
+type memRows struct {
+	columns []string
+	types   []reflect.Type
+	data    [][]interface{}
+	i       int
+}
+
+func (m *memRows) Columns() ([]string, error)           { return m.columns, nil }
+func (m *memRows) ColumnTypes() ([]reflect.Type, error) { return m.types, nil }
+func (m *memRows) ScanStruct(dest interface{}) error    { return nil }
+func (m *memRows) Err() error                           { return nil }
+func (m *memRows) Close() error                         { return nil }
+
+func (m *memRows) Next() bool {
+	m.i++
+	return m.i <= len(m.data)
+}
+
+func (m *memRows) Scan(dest ...interface{}) error {
+	row := m.data[m.i-1]
+	for i, d := range dest {
+		if scanner, ok := d.(sql.Scanner); ok {
+			if err := scanner.Scan(row[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		dv := reflect.ValueOf(d).Elem()
+		if row[i] == nil {
+			dv.Set(reflect.Zero(dv.Type()))
+			continue
+		}
+		v := reflect.ValueOf(row[i])
+		if dv.Kind() == reflect.Pointer && dv.Type() != v.Type() {
+			p := reflect.New(dv.Type().Elem())
+			p.Elem().Set(v)
+			dv.Set(p)
+			continue
+		}
+		dv.Set(v)
+	}
+	return nil
+}
+
+func fishingSpotRows() *memRows {
+	return &memRows{
+		columns: []string{"id", "location_name", "visited"},
+		types:   []reflect.Type{reflect.TypeOf(int32(0)), reflect.TypeOf(""), reflect.TypeOf(time.Time{})},
+		data: [][]interface{}{
+			{int32(1), "Alpine Frove", time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)},
+			{int32(2), `Rivertown, "East"`, nil},
+			{int32(3), nil, nil},
+		},
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	want := `id,locationName,visited
+1,Alpine Frove,01-May-2021
+2,"Rivertown, ""East""",
+3,,
+`
+	var builder strings.Builder
+	err := WriteCSV(&builder, fishingSpotRows(), OutputOptions{
+		ToCamelCase:    true,
+		DateFormat:     "02-Jan-2006",
+		CsvPrintHeader: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != want {
+		t.Errorf("Got %s want %s", builder.String(), want)
+	}
+}
+
+func TestWriteCSVTypes(t *testing.T) {
+	rows := &memRows{
+		columns: []string{"b", "i16", "f32", "f64", "bytes", "ns", "nb", "any"},
+		types: []reflect.Type{
+			reflect.TypeOf(false),
+			reflect.TypeOf(int16(0)),
+			reflect.TypeOf(float32(0)),
+			reflect.TypeOf(float64(0)),
+			reflect.TypeOf([]byte(nil)),
+			reflect.TypeOf(sql.NullString{}),
+			reflect.TypeOf(sql.NullBool{}),
+			reflect.TypeOf((*interface{})(nil)).Elem(),
+		},
+		data: [][]interface{}{
+			{true, int16(7), float32(1.5), 2.25, []byte{0xde, 0xad}, sql.NullString{String: "x", Valid: true}, sql.NullBool{}, []int{1, 2}},
+			{nil, nil, nil, nil, nil, nil, nil, nil},
+		},
+	}
+	want := "true,7,1.5,2.25,\\xdead,x,NULL,\"[1,2]\"\nNULL,NULL,NULL,NULL,NULL,NULL,NULL,NULL\n"
+	var builder strings.Builder
+	err := WriteCSV(&builder, rows, OutputOptions{CsvNullToken: "NULL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != want {
+		t.Errorf("Got %s want %s", builder.String(), want)
+	}
+}
+
+func TestWriteCSVNumeric(t *testing.T) {
+	amount, _ := new(big.Int).SetString("123456789012345678900100", 10)
+	rows := &memRows{
+		columns: []string{"amount", "rate"},
+		types:   []reflect.Type{reflect.TypeOf(PgxNumeric{}), reflect.TypeOf(PgxNumeric{})},
+		data: [][]interface{}{
+			{PgxNumeric{pgtype.Numeric{Int: amount, Exp: -4, Status: pgtype.Present}}, &PgxNumeric{pgtype.Numeric{Int: big.NewInt(-5), Exp: -3, Status: pgtype.Present}}},
+			{PgxNumeric{pgtype.Numeric{Status: pgtype.Null}}, nil},
+		},
+	}
+	want := "12345678901234567890.0100,-0.005\nNULL,NULL\n"
+	var builder strings.Builder
+	err := WriteCSV(&builder, rows, OutputOptions{CsvNullToken: "NULL"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != want {
+		t.Errorf("Got %s want %s", builder.String(), want)
+	}
+}
+
+func TestWriteCSVDialect(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect CsvDialect
+		want    string
+	}{
+		{
+			name:    "tsv",
+			dialect: CsvDialect{Delimiter: '\t', LineTerminator: "\r\n"},
+			want:    "id\tlocation_name\r\n1\tAlpine Frove\r\n2\t\"Rivertown, \"\"East\"\"\"\r\n3\t\r\n",
+		},
+		{
+			name:    "always",
+			dialect: CsvDialect{Delimiter: '|', Quote: CsvQuoteAlways, HeaderAliases: map[string]string{"location_name": "location"}},
+			want:    "\"id\"|\"location\"\n\"1\"|\"Alpine Frove\"\n\"2\"|\"Rivertown, \"\"East\"\"\"\n\"3\"|\"\"\n",
+		},
+		{
+			name:    "never",
+			dialect: CsvDialect{Quote: CsvQuoteNever, BOM: true, HeaderAliases: map[string]string{"location_name": "location"}},
+			want:    "\ufeffid,location\n1,Alpine Frove\n2,Rivertown, \"East\"\n3,\n",
+		},
+	}
+	for _, test := range tests {
+		rows := fishingSpotRows()
+		rows.columns = rows.columns[:2]
+		rows.types = rows.types[:2]
+		for i := range rows.data {
+			rows.data[i] = rows.data[i][:2]
+		}
+		var builder strings.Builder
+		err := WriteCSV(&builder, rows, OutputOptions{
+			CsvPrintHeader: true,
+			CsvDialect:     test.dialect,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if builder.String() != test.want {
+			t.Errorf("%s: Got %q want %q", test.name, builder.String(), test.want)
+		}
+	}
+}
+
+type csvImportRec struct {
+	ID      int16          `db:"id"`
+	Name    *string        `db:"name"`
+	Active  bool           `db:"active"`
+	Score   float32        `db:"score"`
+	Created time.Time      `db:"created"`
+	Notes   sql.NullString `db:"notes"`
+	Data    []byte         `db:"data"`
+}
+
+func TestParseCsvValue(t *testing.T) {
+	header := []string{"id", "name", "active", "score", "created", "notes", "data"}
+	record := []string{"7", "NULL", "true", "1.5", "2021-05-01", "a note", `\xdead`}
+	fi := FluentImport{nullToken: "NULL"}
+	typ := reflect.TypeOf(csvImportRec{})
+	fields, err := fi.fieldIndexes(typ, header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := reflect.New(typ).Elem()
+	err = fi.setFields(rec, fields, header, record)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := csvImportRec{
+		ID:      7,
+		Active:  true,
+		Score:   1.5,
+		Created: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC),
+		Notes:   sql.NullString{String: "a note", Valid: true},
+		Data:    []byte{0xde, 0xad},
+	}
+	if !reflect.DeepEqual(rec.Interface(), want) {
+		t.Errorf("Got %v want %v", rec.Interface(), want)
+	}
+
+	record[0] = "seven"
+	if err = fi.setFields(rec, fields, header, record); err == nil {
+		t.Error("expected a conversion error for id")
+	}
+	if _, err = fi.fieldIndexes(typ, []string{"missing"}); err == nil {
+		t.Error("expected an error for an unmapped column")
+	}
+}
@@ -7,9 +7,9 @@ import (
 )
 
 const selectkey = "select"
-
-//const updatekey = "update"
-//const insertkey = "insert"
+const updatekey = "update"
+const deletekey = "delete"
+const insertkey = "insert"
 
 type RowFunction func(r Rows) error
 
@@ -19,6 +19,7 @@ type Rows interface {
 	Next() bool
 	Scan(dest ...interface{}) error
 	ScanStruct(dest interface{}) error
+	Err() error
 	Close() error
 }
 
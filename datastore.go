@@ -1,28 +1,57 @@
 package goquery
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"io"
-
-	"github.com/jackc/pgconn"
+	"reflect"
 )
 
 type RecordHandler func(interface{}) error
 
 type BindParamTemplateFunction func(field string, i int) string
 type SequenceTemplateFunction func(sequence string) string
+type ColumnTypeTemplateFunction func(typ reflect.Type) (string, error)
+type ReturningTemplateFunction func(idfield string) string
+type UpsertTemplateFunction func(table string, fields []string, binds []string, conflict []string, update []string) (string, error)
 type UrlTemplateFunction func(config *RdbmsConfig) string
+type EmptyListTemplateFunction func(colType string) string
+type LimitOffsetTemplateFunction func(limit int, offset int) string
+type QuoteIdentifierTemplateFunction func(name string) string
+type RestartIdentityTemplateFunction func(table string, column string, start int64) string
 
 const (
 	DEST OutputFormat = iota
 	JSON
 	CSV
+	NDJSON
 )
 
 type DbDialect struct {
 	TableExistsStmt string
 	Bind            BindParamTemplateFunction
 	Seq             SequenceTemplateFunction
+	Upsert          UpsertTemplateFunction
+	Returning       ReturningTemplateFunction
+	ColumnType      ColumnTypeTemplateFunction
 	Url             UrlTemplateFunction
+	//EmptyList returns a subquery with no rows of colType that replaces an in list
+	//bound to an empty slice
+	EmptyList EmptyListTemplateFunction
+	//LimitOffset returns the clause that skips offset rows and returns at most limit rows.
+	//a limit of zero returns all remaining rows
+	LimitOffset LimitOffsetTemplateFunction
+	//QuoteIdentifier quotes a validated table or column name applied to a statement
+	QuoteIdentifier QuoteIdentifierTemplateFunction
+	//MaxInList is the largest number of slice elements that can be expanded into an
+	//in list.  Zero is unlimited
+	MaxInList int
+	//RestartIdentity returns the statement that restarts an identity column at start
+	RestartIdentity RestartIdentityTemplateFunction
+	//ReturningInto is true when the returning clause writes the id to an out bind parameter
+	//rather than returning it as a result row
+	ReturningInto bool
 }
 
 type QueryInput struct {
@@ -31,7 +60,13 @@ type QueryInput struct {
 	Statement    string
 	Suffix       string
 	BindParams   []interface{}
+	NamedParams  interface{}
 	StmtAppends  []interface{}
+	Criteria     []Criterion
+	Order        []Order
+	Limit        int
+	Offset       int
+	Page         *Page
 	PanicOnErr   bool
 	LogSql       bool
 }
@@ -44,6 +79,24 @@ type QueryOutput struct {
 }
 
 type InsertInput struct {
+	Dataset         DataSet
+	StatementKey    string
+	Records         interface{}
+	Batch           bool
+	BatchSize       int
+	PanicOnErr      bool
+	Upsert          bool
+	ConflictColumns []string
+	UpdateColumns   []string
+	ReturnId        bool
+	Copy            bool
+	//KeepIds inserts the dbid field values of the records rather than generated ids
+	KeepIds bool
+	//named holds the parameter names of an insert command bound by name
+	named []string
+}
+
+type UpdateInput struct {
 	Dataset    DataSet
 	Records    interface{}
 	Batch      bool
@@ -51,31 +104,67 @@ type InsertInput struct {
 	PanicOnErr bool
 }
 
+type DeleteInput struct {
+	Dataset     DataSet
+	Records     interface{}
+	Suffix      string
+	BindParams  []interface{}
+	NamedParams interface{}
+	PanicOnErr  bool
+}
+
 type OutputOptions struct {
 	ToCamelCase    bool
 	IsArray        bool
 	DateFormat     string
 	OmitNull       bool
 	CsvPrintHeader bool
+	CsvNullToken   string
+	CsvDialect     CsvDialect
+	//render dotted column names such as "owner.name" as nested json objects
+	JsonNested bool
+	//merge consecutive rows with the same JsonGroupBy column value into one json object.
+	//columns prefixed with a JsonChildren name, such as "spots.name", are
+	//collected into an array of child objects named "spots"
+	JsonGroupBy  string
+	JsonChildren []string
 }
 
 type DataStore interface {
 	Connection() interface{}
+	Dialect() DbDialect
 	NewTransaction() (Tx, error)
+	NewTransactionContext(ctx context.Context) (Tx, error)
 	Transaction(tf TransactionFunction) error
+	TransactionContext(ctx context.Context, tf TransactionFunction) error
 	Fetch(tx *Tx, input QueryInput, output QueryOutput, dest any) error
+	FetchContext(ctx context.Context, tx *Tx, input QueryInput, output QueryOutput, dest any) error
 	FetchRows(tx *Tx, input QueryInput) (Rows, error)
+	FetchRowsContext(ctx context.Context, tx *Tx, input QueryInput) (Rows, error)
 	GetJSON(writer io.Writer, input QueryInput, jo OutputOptions) error
+	GetJSONContext(ctx context.Context, tx *Tx, writer io.Writer, input QueryInput, jo OutputOptions) error
 	GetCSV(input QueryInput, co OutputOptions) (string, error)
+	GetCSVContext(ctx context.Context, tx *Tx, input QueryInput, co OutputOptions) (string, error)
 	Select(stmt ...string) *FluentSelect
 	Insert(ds DataSet) *FluentInsert
 	//InsertRecs(ds DataSet, recs interface{}, batch bool, batchSize int, tx *Tx) error
 	InsertRecs(tx *Tx, input InsertInput) error
-	//UpdateRecs(ds DataSet, recs interface{}, batch bool, batchSize int, tx *Tx) error
+	InsertRecsContext(ctx context.Context, tx *Tx, input InsertInput) (int64, error)
+	Update(ds DataSet) *FluentUpdate
+	UpdateRecs(tx *Tx, input UpdateInput) (int64, error)
+	UpdateRecsContext(ctx context.Context, tx *Tx, input UpdateInput) (int64, error)
+	Delete(ds DataSet) *FluentDelete
+	DeleteRecs(tx *Tx, input DeleteInput) (int64, error)
+	DeleteRecsContext(ctx context.Context, tx *Tx, input DeleteInput) (int64, error)
+	Import(ds DataSet) *FluentImport
 	Exec(tx *Tx, stmt string, params ...interface{}) error
+	ExecContext(ctx context.Context, tx *Tx, stmt string, params ...interface{}) error
 	Execr(tx *Tx, stmt string, params ...interface{}) (ExecResult, error)
+	ExecrContext(ctx context.Context, tx *Tx, stmt string, params ...interface{}) (ExecResult, error)
 	MustExec(tx *Tx, stmt string, params ...interface{})
+	MustExecContext(ctx context.Context, tx *Tx, stmt string, params ...interface{})
 	MustExecr(tx *Tx, stmt string, params ...interface{}) ExecResult
+	MustExecrContext(ctx context.Context, tx *Tx, stmt string, params ...interface{}) ExecResult
 	//RecordsetIterator(s Select, handler RecordHandler)
 }
 
@@ -83,11 +172,36 @@ type Batch interface {
 	Queue(stmt string, params ...interface{})
 }
 
+// BatchResult reads the results of a sent batch in the order the statements were queued.
+// Close must be called once the results are read.
 type BatchResult interface {
-	Exec() (pgconn.CommandTag, error)
+	Exec() (ExecResult, error)
+	QueryRow() Row
 	Close() error
 }
 
+// ErrCopyNotSupported is returned by RdbmsDb implementations that cannot bulk load
+// with COPY.  Copy inserts on those stores fall back to batch inserts.
+var ErrCopyNotSupported = errors.New("copy is not supported by this store")
+
+// BatchError reports the index of the record that caused a batch statement to fail.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (be *BatchError) Error() string {
+	return fmt.Sprintf("batch failed on record %d: %s", be.Index, be.Err)
+}
+
+func (be *BatchError) Unwrap() error {
+	return be.Err
+}
+
+type Row interface {
+	Scan(dest ...interface{}) error
+}
+
 type ExecResult interface {
 	RowsAffected() int64
 }
@@ -1,10 +1,24 @@
 package goquery
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const defaultCommitSize = 1000
+
 type TransferOptions struct {
 	CreateTable bool
 	CommitSize  int
 }
 
+// ETL copies records from a source store to a destination store.  Records are read
+// with the source dataset select (or sourceStmtKey) into the dataset TableFields type
+// and written to the destination with batch inserts, committing every CommitSize records.
+// Records keep their dbid values rather than taking ids generated by the destination.
 type ETL struct {
 	source        DataStore
 	sourceStmtKey string
@@ -13,38 +27,161 @@ type ETL struct {
 	options       TransferOptions
 }
 
-/*
-func (etl *ETL) copyData(qi QueryInput) (err error) {
-	rows, err := etl.source.FetchRows(qi)
+func NewETL(source DataStore, dest DataStore, options TransferOptions) *ETL {
+	return &ETL{
+		source:  source,
+		dest:    dest,
+		options: options,
+	}
+}
+
+// SourceStatementKey selects the source records with the source dataset statement stored under key.
+func (etl *ETL) SourceStatementKey(key string) *ETL {
+	etl.sourceStmtKey = key
+	return etl
+}
+
+// DestStatementKey inserts the records with the destination dataset statement stored under key.
+// The statement is bound with every db tagged field, including the dbid field, in struct order.
+func (etl *ETL) DestStatementKey(key string) *ETL {
+	etl.destStmtKey = key
+	return etl
+}
+
+// Transfer copies the source dataset into the dest dataset and returns the number of records
+// written.  Both datasets must share the same TableFields type.  Each CommitSize chunk is
+// committed in its own transaction, so a failure leaves the previously committed chunks in place.
+// A table created for the transfer has an identity column for an AUTOINCREMENT id, restarted after
+// the copied ids.  The idsequence of a SEQUENCE id is not created.
+func (etl *ETL) Transfer(ctx context.Context, source DataSet, dest DataSet) (int64, error) {
+	if source.Fields() == nil {
+		return 0, errors.New("the source dataset must define TableFields")
+	}
+	typ := reflect.TypeOf(source.Fields())
+	if dest.Fields() == nil || reflect.TypeOf(dest.Fields()) != typ {
+		return 0, errors.New("the source and destination datasets must have the same TableFields type")
+	}
+
+	created := false
+	if etl.options.CreateTable {
+		var err error
+		created, err = etl.createTable(ctx, dest)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	commitSize := etl.options.CommitSize
+	if commitSize <= 0 {
+		commitSize = defaultCommitSize
+	}
+
+	rows, err := etl.source.Select().
+		Context(ctx).
+		DataSet(source).
+		StatementKey(etl.sourceStmtKey).
+		FetchRows()
 	if err != nil {
-		return err
+		return 0, err
 	}
 	defer rows.Close()
-	typ := reflect.TypeOf(qi.DataSet.Attributes()).Elem()
-	typeP := reflect.New(typ).Elem().Addr()
-	structRef := typeP.Interface()
-	var i int = 0
-
 
-		for rows.Next() {
-			i++
-			err = rows.StructScan(structRef)
+	chunk := reflect.MakeSlice(reflect.SliceOf(typ), commitSize, commitSize)
+	var count int64
+	i := 0
+	for rows.Next() {
+		rec := chunk.Index(i)
+		rec.Set(reflect.Zero(typ))
+		err = rows.ScanStruct(rec.Addr().Interface())
+		if err != nil {
+			return count, err
+		}
+		i++
+		if i == commitSize {
+			n, err := etl.write(ctx, dest, chunk)
+			count += n
 			if err != nil {
-				panic(err)
-			}
-			if i%etl.options.CommitSize == 0 {
-				err = etl.dest.Commit()
-				if err != nil {
-					panic(err)
-				}
-				err = etl.dest.StartTransaction()
-				if err != nil {
-					panic(err)
-				}
+				return count, err
 			}
-			etl.dest.CopyRow(table, i, structRef)
+			i = 0
 		}
+	}
+	if err = rows.Err(); err != nil {
+		return count, err
+	}
+	if i > 0 {
+		n, err := etl.write(ctx, dest, chunk.Slice(0, i))
+		count += n
+		if err != nil {
+			return count, err
+		}
+	}
+	if created {
+		return count, etl.restartIdentity(ctx, dest)
+	}
+	return count, nil
+}
+
+func (etl *ETL) write(ctx context.Context, dest DataSet, recs reflect.Value) (int64, error) {
+	return etl.dest.Insert(dest).
+		Context(ctx).
+		StatementKey(etl.destStmtKey).
+		Records(recs.Interface()).
+		KeepIds(true).
+		Batch(true).
+		BatchSize(recs.Len()).
+		Execr()
+}
 
+// createTable creates the destination table from the dataset TableFields if it does not exist
+// and reports whether the table was created.
+func (etl *ETL) createTable(ctx context.Context, ds DataSet) (bool, error) {
+	dialect := etl.dest.Dialect()
+	schema := ""
+	table := ds.Entity()
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		schema = table[:i]
+		table = table[i+1:]
+	}
+	var tableCount int
+	err := etl.dest.Select(dialect.TableExistsStmt).
+		Context(ctx).
+		Params(schema, table).
+		Dest(&tableCount).
+		Fetch()
+	if err != nil {
+		return false, fmt.Errorf("unable to check for table %s: %s", ds.Entity(), err)
+	}
+	if tableCount > 0 {
+		return false, nil
+	}
+	stmt, err := ToCreateTable(ds, dialect)
+	if err != nil {
+		return false, err
+	}
+	return true, etl.dest.ExecContext(ctx, NoTx, stmt)
+}
+
+// restartIdentity restarts the identity column of an AUTOINCREMENT id after the largest
+// copied id, so that ids generated for later inserts do not collide with the copied ids.
+func (etl *ETL) restartIdentity(ctx context.Context, ds DataSet) error {
+	dialect := etl.dest.Dialect()
+	typ := reflect.TypeOf(ds.Fields())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		column, ok := field.Tag.Lookup("db")
+		if !ok || field.Tag.Get("dbid") != "AUTOINCREMENT" || dialect.RestartIdentity == nil {
+			continue
+		}
+		var start int64
+		err := etl.dest.Select(fmt.Sprintf("select coalesce(max(%s),0)+1 from %s", column, ds.Entity())).
+			Context(ctx).
+			Dest(&start).
+			Fetch()
+		if err != nil {
+			return err
+		}
+		return etl.dest.ExecContext(ctx, NoTx, dialect.RestartIdentity(ds.Entity(), column, start))
+	}
 	return nil
 }
-*/
@@ -0,0 +1,73 @@
+package goquery
+
+import "context"
+
+// FluentDelete deletes either the records supplied to Records, using their dbid
+// tagged field as the key, or every row matching the Suffix criteria.  Setting both
+// Records and a Suffix is an error.  A DataSet "delete" command whose :name or @name
+// parameters are all db tagged fields is bound by name from each record.
+type FluentDelete struct {
+	store      DataStore
+	ctx        context.Context
+	ds         DataSet
+	tx         *Tx
+	records    interface{}
+	suffix     string
+	params     []interface{}
+	named      interface{}
+	panicOnErr bool
+}
+
+// Context sets the context used to run the delete.
+func (d *FluentDelete) Context(ctx context.Context) *FluentDelete {
+	d.ctx = ctx
+	return d
+}
+
+func (d *FluentDelete) Tx(tx *Tx) *FluentDelete {
+	d.tx = tx
+	return d
+}
+
+// Records accepts a single struct, a pointer to a struct, or a slice of structs.
+func (d *FluentDelete) Records(recs interface{}) *FluentDelete {
+	d.records = recs
+	return d
+}
+
+// Suffix is appended to "delete from <entity>", for example "where id>$1".
+// It is string concatenation.  Never pass user input in the suffix, use Params.
+func (d *FluentDelete) Suffix(suffix string) *FluentDelete {
+	d.suffix = suffix
+	return d
+}
+
+func (d *FluentDelete) Params(params ...interface{}) *FluentDelete {
+	d.params = params
+	return d
+}
+
+// NamedParams binds :name or @name suffix parameters from a map with string keys
+// or a struct with db tagged fields.
+func (d *FluentDelete) NamedParams(params interface{}) *FluentDelete {
+	d.named = params
+	return d
+}
+
+func (d *FluentDelete) PanicOnErr(panicOnErr bool) *FluentDelete {
+	d.panicOnErr = panicOnErr
+	return d
+}
+
+// Execute runs the delete and returns the total number of rows affected.
+func (d *FluentDelete) Execute() (int64, error) {
+	di := DeleteInput{
+		Dataset:     d.ds,
+		Records:     d.records,
+		Suffix:      d.suffix,
+		BindParams:  d.params,
+		NamedParams: d.named,
+		PanicOnErr:  d.panicOnErr,
+	}
+	return d.store.DeleteRecsContext(d.ctx, d.tx, di)
+}
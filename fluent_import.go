@@ -0,0 +1,523 @@
+package goquery
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/stoewer/go-strcase"
+)
+
+// ImportError reports a record that could not be imported and the line
+// of the input it was read from.  For json imports Line is the line the
+// record starts on.
+type ImportError struct {
+	Line int
+	Err  error
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("import failed on line %d: %s", e.Line, e.Err)
+}
+
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// ImportResult is the number of records inserted by an import and the
+// records that were skipped when SkipBadRows is set.
+type ImportResult struct {
+	Records int64
+	Skipped []ImportError
+}
+
+// FluentImport reads records from a file into the TableFields type of a dataset
+// and inserts them in batches.  Each batch is inserted in its own transaction
+// unless a transaction is supplied with Tx.
+type FluentImport struct {
+	store       DataStore
+	ctx         context.Context
+	ds          DataSet
+	tx          *Tx
+	batchSize   int
+	headerMap   map[string]string
+	skipBadRows bool
+	delimiter   rune
+	nullToken   string
+	dateFormat  string
+	camelCase   bool
+}
+
+// Context sets the context used to run the inserts.
+func (i *FluentImport) Context(ctx context.Context) *FluentImport {
+	i.ctx = ctx
+	return i
+}
+
+func (i *FluentImport) Tx(tx *Tx) *FluentImport {
+	i.tx = tx
+	return i
+}
+
+func (i *FluentImport) BatchSize(bs int) *FluentImport {
+	i.batchSize = bs
+	return i
+}
+
+// HeaderMap maps csv column or json field names to db column names.  Input names are matched
+// to db tagged fields by name unless they are mapped.  Map a name to "-" to ignore it.
+func (i *FluentImport) HeaderMap(headerMap map[string]string) *FluentImport {
+	i.headerMap = headerMap
+	return i
+}
+
+// SkipBadRows skips records that cannot be read or converted and reports them
+// in the ImportResult instead of stopping the import.  Invalid json syntax still
+// stops a json import, since the records that follow it cannot be read.  Records
+// rejected by the database are skipped by inserting their batch again without them,
+// which is only possible when each batch has its own transaction.  With a Tx the
+// first rejected record stops the import.  Batches inserted before a failure that
+// stops the import stay committed.
+func (i *FluentImport) SkipBadRows(skip bool) *FluentImport {
+	i.skipBadRows = skip
+	return i
+}
+
+// Delimiter sets the csv field delimiter.  The default is a comma.
+func (i *FluentImport) Delimiter(delimiter rune) *FluentImport {
+	i.delimiter = delimiter
+	return i
+}
+
+// NullToken sets the input value read as null.  Null values set pointer fields to nil
+// and other fields to their zero value.  The default is an empty field.
+func (i *FluentImport) NullToken(token string) *FluentImport {
+	i.nullToken = token
+	return i
+}
+
+// DateFormat sets the layout used to parse time fields.  When it is not set, RFC3339,
+// the time.Time String format and common date and timestamp layouts are accepted.
+func (i *FluentImport) DateFormat(dateFormat string) *FluentImport {
+	i.dateFormat = dateFormat
+	return i
+}
+
+// CamelCase matches json fields to the camel case form of the db tags, mirroring
+// the ToCamelCase output option.
+func (i *FluentImport) CamelCase(camelCase bool) *FluentImport {
+	i.camelCase = camelCase
+	return i
+}
+
+// FromCsv imports a csv file with a header row.
+func (i *FluentImport) FromCsv(reader io.Reader) (ImportResult, error) {
+	var result ImportResult
+	typ, err := i.recordType()
+	if err != nil {
+		return result, err
+	}
+
+	cr := csv.NewReader(reader)
+	if i.delimiter != 0 {
+		cr.Comma = i.delimiter
+	}
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return result, fmt.Errorf("unable to read csv header: %s", err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	fields, err := i.fieldIndexes(typ, header)
+	if err != nil {
+		return result, err
+	}
+
+	loader := i.newLoader(typ, &result)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		var pe *csv.ParseError
+		if errors.As(err, &pe) {
+			if err = loader.skip(pe.StartLine, pe.Err); err != nil {
+				return result, err
+			}
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		line, _ := cr.FieldPos(0)
+		if len(record) != len(header) {
+			err = fmt.Errorf("wrong number of fields: got %d want %d", len(record), len(header))
+			if err = loader.skip(line, err); err != nil {
+				return result, err
+			}
+			continue
+		}
+		rec := reflect.New(typ).Elem()
+		err = i.setFields(rec, fields, header, record)
+		if err != nil {
+			if err = loader.skip(line, err); err != nil {
+				return result, err
+			}
+			continue
+		}
+		if err = loader.add(rec, line); err != nil {
+			return result, err
+		}
+	}
+	return result, loader.flush()
+}
+
+// FromJson imports a json array of objects or a stream of json objects such as
+// newline delimited json.  Time fields are parsed with DateFormat when it is set.
+// Records that are valid json but not objects of the dataset fields can be skipped
+// with SkipBadRows.  Invalid json syntax always stops the import with an ImportError.
+func (i *FluentImport) FromJson(reader io.Reader) (ImportResult, error) {
+	var result ImportResult
+	typ, err := i.recordType()
+	if err != nil {
+		return result, err
+	}
+	fields := i.jsonFields(typ)
+
+	br := bufio.NewReader(reader)
+	isArray, skipped, err := jsonIsArray(br)
+	if err != nil {
+		return result, err
+	}
+	lr := &lineReader{r: br, line: skipped + 1}
+	dec := json.NewDecoder(lr)
+	if isArray {
+		if _, err = dec.Token(); err != nil {
+			return result, err
+		}
+	}
+
+	loader := i.newLoader(typ, &result)
+	for {
+		if isArray && !dec.More() {
+			break
+		}
+		var raw json.RawMessage
+		start := jsonValueStart(dec)
+		err = dec.Decode(&raw)
+		if err == io.EOF && !isArray {
+			break
+		}
+		if err != nil {
+			//the decoder cannot continue after invalid json, so the error is not skipped
+			return result, &ImportError{lr.lineAt(start), err}
+		}
+		line := lr.lineAt(dec.InputOffset() - int64(len(raw)))
+		rec := reflect.New(typ).Elem()
+		var obj map[string]json.RawMessage
+		err = json.Unmarshal(raw, &obj)
+		if err == nil {
+			err = i.setJsonFields(rec, fields, obj)
+		}
+		if err != nil {
+			if err = loader.skip(line, err); err != nil {
+				return result, err
+			}
+			continue
+		}
+		if err = loader.add(rec, line); err != nil {
+			return result, err
+		}
+	}
+	if isArray {
+		if _, err = dec.Token(); err != nil {
+			return result, err
+		}
+	}
+	return result, loader.flush()
+}
+
+// jsonIsArray reports whether the first non whitespace character of the input opens a json array,
+// along with the number of lines skipped before it.
+func jsonIsArray(br *bufio.Reader) (bool, int, error) {
+	lines := 0
+	for {
+		b, err := br.Peek(1)
+		if err == io.EOF {
+			return false, lines, nil
+		}
+		if err != nil {
+			return false, lines, err
+		}
+		switch b[0] {
+		case '\n':
+			lines++
+			br.ReadByte()
+		case ' ', '\t', '\r':
+			br.ReadByte()
+		default:
+			return b[0] == '[', lines, nil
+		}
+	}
+}
+
+// jsonValueStart returns the input offset of the next json value, skipping the whitespace
+// and array comma already buffered by the decoder.
+func jsonValueStart(dec *json.Decoder) int64 {
+	offset := dec.InputOffset()
+	buf, ok := dec.Buffered().(io.ByteReader)
+	if !ok {
+		return offset
+	}
+	for {
+		b, err := buf.ReadByte()
+		if err != nil {
+			return offset
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n', ',':
+			offset++
+		default:
+			return offset
+		}
+	}
+}
+
+// lineReader records the newlines read from r so that json decoder offsets can be
+// reported as input lines.
+type lineReader struct {
+	r        io.Reader
+	offset   int64
+	newlines []int64
+	line     int
+}
+
+func (lr *lineReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	for i, b := range p[:n] {
+		if b == '\n' {
+			lr.newlines = append(lr.newlines, lr.offset+int64(i))
+		}
+	}
+	lr.offset += int64(n)
+	return n, err
+}
+
+// lineAt returns the line of the input offset.  Offsets must not decrease between calls.
+func (lr *lineReader) lineAt(offset int64) int {
+	i := 0
+	for i < len(lr.newlines) && lr.newlines[i] < offset {
+		i++
+	}
+	lr.line += i
+	lr.newlines = lr.newlines[i:]
+	return lr.line
+}
+
+// jsonFields maps json field names to struct field indexes.  Ignored fields map to nil.
+func (i *FluentImport) jsonFields(typ reflect.Type) map[string][]int {
+	dbFields := dbFieldIndexes(typ)
+	fields := make(map[string][]int)
+	for col, index := range dbFields {
+		if i.camelCase {
+			col = strcase.LowerCamelCase(col)
+		}
+		fields[col] = index
+	}
+	for name, col := range i.headerMap {
+		if col == "-" {
+			fields[name] = nil
+		} else if index, ok := dbFields[col]; ok {
+			fields[name] = index
+		}
+	}
+	return fields
+}
+
+func (i *FluentImport) setJsonFields(rec reflect.Value, fields map[string][]int, obj map[string]json.RawMessage) error {
+	for name, raw := range obj {
+		index, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("json field %s does not match a db field of %s", name, rec.Type())
+		}
+		if index == nil {
+			continue
+		}
+		err := parseJsonValue(rec.FieldByIndex(index), raw, i.dateFormat)
+		if err != nil {
+			return fmt.Errorf("field %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// parseJsonValue unmarshals a json value into field.  Time fields are parsed with
+// dateFormat when it is set, and sql scanners that are not json unmarshalers, such
+// as the sql.Null types, scan the decoded value.
+func parseJsonValue(field reflect.Value, raw json.RawMessage, dateFormat string) error {
+	if string(raw) == "null" {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	if field.Kind() == reflect.Pointer {
+		ptr := reflect.New(field.Type().Elem())
+		err := parseJsonValue(ptr.Elem(), raw, dateFormat)
+		if err != nil {
+			return err
+		}
+		field.Set(ptr)
+		return nil
+	}
+	dest := field.Addr().Interface()
+	if _, ok := dest.(*time.Time); ok && dateFormat != "" {
+		var val string
+		if err := json.Unmarshal(raw, &val); err != nil {
+			return err
+		}
+		t, err := parseCsvTime(val, dateFormat)
+		if err != nil {
+			return err
+		}
+		field.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if _, ok := dest.(json.Unmarshaler); !ok {
+		if scanner, ok := dest.(sql.Scanner); ok {
+			var val interface{}
+			if err := json.Unmarshal(raw, &val); err != nil {
+				return err
+			}
+			return scanner.Scan(val)
+		}
+	}
+	return json.Unmarshal(raw, dest)
+}
+
+func (i *FluentImport) recordType() (reflect.Type, error) {
+	if i.ds == nil || i.ds.Fields() == nil {
+		return nil, errors.New("the import dataset must define TableFields")
+	}
+	typ := reflect.TypeOf(i.ds.Fields())
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid TableFields type %s.  expected a struct", typ)
+	}
+	return typ, nil
+}
+
+// fieldIndexes returns the struct field index for each input column, or nil for ignored columns.
+func (i *FluentImport) fieldIndexes(typ reflect.Type, header []string) ([][]int, error) {
+	dbFields := dbFieldIndexes(typ)
+	fields := make([][]int, len(header))
+	for c, col := range header {
+		if mapped, ok := i.headerMap[col]; ok {
+			col = mapped
+		}
+		if col == "-" {
+			continue
+		}
+		index, ok := dbFields[col]
+		if !ok {
+			return nil, fmt.Errorf("import column %s does not match a db field of %s", col, typ)
+		}
+		fields[c] = index
+	}
+	return fields, nil
+}
+
+func (i *FluentImport) setFields(rec reflect.Value, fields [][]int, header []string, record []string) error {
+	for c, index := range fields {
+		if index == nil {
+			continue
+		}
+		err := parseCsvValue(rec.FieldByIndex(index), record[c], i.nullToken, i.dateFormat)
+		if err != nil {
+			return fmt.Errorf("column %s: %s", header[c], err)
+		}
+	}
+	return nil
+}
+
+func (i *FluentImport) newLoader(typ reflect.Type, result *ImportResult) *importLoader {
+	batchSize := i.batchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	return &importLoader{
+		fi:        i,
+		result:    result,
+		batchSize: batchSize,
+		recs:      reflect.MakeSlice(reflect.SliceOf(typ), 0, batchSize),
+	}
+}
+
+// importLoader collects imported records and inserts them every batchSize records.
+type importLoader struct {
+	fi        *FluentImport
+	result    *ImportResult
+	batchSize int
+	recs      reflect.Value
+	lines     []int
+}
+
+// skip records a bad input line, or returns it as an error when bad rows are not skipped.
+func (l *importLoader) skip(line int, err error) error {
+	if !l.fi.skipBadRows {
+		return &ImportError{line, err}
+	}
+	l.result.Skipped = append(l.result.Skipped, ImportError{line, err})
+	return nil
+}
+
+func (l *importLoader) add(rec reflect.Value, line int) error {
+	l.recs = reflect.Append(l.recs, rec)
+	l.lines = append(l.lines, line)
+	if l.recs.Len() == l.batchSize {
+		return l.flush()
+	}
+	return nil
+}
+
+// flush inserts the collected records.  When bad rows are skipped and the batch has its
+// own transaction, a record rejected by the database is skipped and the rolled back batch
+// is inserted again without it.
+func (l *importLoader) flush() error {
+	for l.recs.Len() > 0 {
+		rows, err := l.fi.store.Insert(l.fi.ds).
+			Context(l.fi.ctx).
+			Tx(l.fi.tx).
+			Records(l.recs.Interface()).
+			Batch(true).
+			BatchSize(l.recs.Len()).
+			Execr()
+		l.result.Records += rows
+		if err == nil {
+			break
+		}
+		var be *BatchError
+		if !errors.As(err, &be) || be.Index >= len(l.lines) {
+			return err
+		}
+		if !l.fi.skipBadRows || l.fi.tx != nil {
+			return &ImportError{l.lines[be.Index], be.Err}
+		}
+		l.result.Skipped = append(l.result.Skipped, ImportError{l.lines[be.Index], be.Err})
+		l.recs = reflect.AppendSlice(l.recs.Slice(0, be.Index), l.recs.Slice(be.Index+1, l.recs.Len()))
+		l.lines = append(l.lines[:be.Index], l.lines[be.Index+1:]...)
+	}
+	l.recs = l.recs.Slice(0, 0)
+	l.lines = l.lines[:0]
+	return nil
+}
@@ -1,18 +1,43 @@
 package goquery
 
+import "context"
+
 type FluentInsert struct {
 	store      DataStore
+	ctx        context.Context
 	ds         DataSet
+	stmtKey    string
 	batch      bool
 	batchSize  int
 	tx         *Tx
 	records    interface{}
 	panicOnErr bool
-	//return id
+	upsert     bool
+	conflict   []string
+	update     []string
+	returnId   bool
+	copyFrom   bool
+	keepIds    bool
 }
 
 const defaultBatchSize = 100
 
+// Context sets the context used to run the insert.
+func (i *FluentInsert) Context(ctx context.Context) *FluentInsert {
+	i.ctx = ctx
+	return i
+}
+
+// StatementKey uses the dataset statement stored under key instead of a generated insert.
+// The statement must bind the record values in the order returned by StructToIArray, unless
+// its parameters are :name or @name parameters that are all db tagged fields, which are bound
+// by name from each record.  The same applies to a DataSet "insert" command.  Statements bound
+// by name cannot be used with ReturnId.
+func (i *FluentInsert) StatementKey(key string) *FluentInsert {
+	i.stmtKey = key
+	return i
+}
+
 func (i *FluentInsert) Tx(tx *Tx) *FluentInsert {
 	i.tx = tx
 	return i
@@ -38,14 +63,68 @@ func (i *FluentInsert) PanicOnErr(panicOnErr bool) *FluentInsert {
 	return i
 }
 
+// Upsert resolves conflicts on the conflict columns by updating the update columns
+// of the existing row.  If update is empty, conflicting records are skipped.
+// Postgres renders this as INSERT ... ON CONFLICT and oracle as MERGE.  Conflict
+// and update columns must be db tagged fields, and conflict columns cannot also be
+// update columns.  Oracle conflict columns must be bound fields rather than sequence ids.
+func (i *FluentInsert) Upsert(conflict []string, update []string) *FluentInsert {
+	i.upsert = true
+	i.conflict = conflict
+	i.update = update
+	return i
+}
+
+// ReturnId populates the dbid tagged field of each record with the id assigned
+// by the database.  Records must be passed as a pointer to a struct or as a slice.
+// Returning ids is not supported with oracle upserts and returns an error.  Postgres
+// upserts return the id of updated rows, but records skipped by a conflict with no
+// update columns return no row, so their id is left unchanged and they are not counted
+// in the rows inserted.
+func (i *FluentInsert) ReturnId(returnId bool) *FluentInsert {
+	i.returnId = returnId
+	return i
+}
+
+// Copy bulk loads a slice of records using COPY FROM on the pgx store.  Columns
+// and values are taken from the db tags of the record type.  Stores that do not
+// support COPY fall back to batch inserts.  Copy cannot be used with a StatementKey
+// or a dataset insert statement.
+func (i *FluentInsert) Copy(useCopy bool) *FluentInsert {
+	i.copyFrom = useCopy
+	return i
+}
+
+// KeepIds writes the dbid tagged field of each record with the value it already has
+// instead of an id generated by the database sequence or identity.  A StatementKey
+// statement is bound with every db tagged field, ids included, in struct order.
+// KeepIds cannot be used with upserts, ReturnId or Copy.
+func (i *FluentInsert) KeepIds(keepIds bool) *FluentInsert {
+	i.keepIds = keepIds
+	return i
+}
+
 func (i *FluentInsert) Execute() error {
-	//return i.store.InsertRecs(i.ds, i.records, i.batch, i.batchSize, i.tx)
+	_, err := i.Execr()
+	return err
+}
+
+// Execr runs the insert and returns the total number of rows inserted.
+// Batch failures are reported as a *BatchError with the index of the failing record.
+func (i *FluentInsert) Execr() (int64, error) {
 	ii := InsertInput{
-		Dataset:    i.ds,
-		Records:    i.records,
-		Batch:      i.batch,
-		BatchSize:  i.batchSize,
-		PanicOnErr: i.panicOnErr,
+		Dataset:         i.ds,
+		StatementKey:    i.stmtKey,
+		Records:         i.records,
+		Batch:           i.batch,
+		BatchSize:       i.batchSize,
+		PanicOnErr:      i.panicOnErr,
+		Upsert:          i.upsert,
+		ConflictColumns: i.conflict,
+		UpdateColumns:   i.update,
+		ReturnId:        i.returnId,
+		Copy:            i.copyFrom,
+		KeepIds:         i.keepIds,
 	}
-	return i.store.InsertRecs(i.tx, ii)
+	return i.store.InsertRecsContext(i.ctx, i.tx, ii)
 }
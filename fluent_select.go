@@ -3,6 +3,7 @@ package goquery
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"io"
 )
 
@@ -10,6 +11,7 @@ type OutputFormat uint8
 
 type FluentSelect struct {
 	store DataStore
+	ctx   context.Context
 	tx    *Tx
 	qi    QueryInput
 	qo    QueryOutput
@@ -21,6 +23,13 @@ func (s *FluentSelect) DataSet(ds DataSet) *FluentSelect {
 	return s
 }
 
+// Context sets the context used to run the query.  Cancelling the context
+// or exceeding its deadline aborts the query in the underlying driver.
+func (s *FluentSelect) Context(ctx context.Context) *FluentSelect {
+	s.ctx = ctx
+	return s
+}
+
 func (s *FluentSelect) Tx(tx *Tx) *FluentSelect {
 	s.tx = tx
 	return s
@@ -62,6 +71,41 @@ func (s *FluentSelect) IsJsonArray(isJsonArray bool) *FluentSelect {
 	return s
 }
 
+// JsonNested renders dotted column aliases such as "owner.name" and "owner.email"
+// as nested json objects.
+func (s *FluentSelect) JsonNested(nested bool) *FluentSelect {
+	s.qo.Options.JsonNested = nested
+	return s
+}
+
+// JsonGroupBy merges consecutive rows with the same keyColumn value into a single json
+// object.  Columns prefixed with one of the children names and a dot are collected into
+// an array of child objects under that name, so the query should be ordered by keyColumn.
+func (s *FluentSelect) JsonGroupBy(keyColumn string, children ...string) *FluentSelect {
+	s.qo.Options.JsonGroupBy = keyColumn
+	s.qo.Options.JsonChildren = children
+	return s
+}
+
+func (s *FluentSelect) CsvHeader(printHeader bool) *FluentSelect {
+	s.qo.Options.CsvPrintHeader = printHeader
+	return s
+}
+
+// CsvNullToken sets the value written to csv output for null columns.
+// Null columns are written as empty fields by default.
+func (s *FluentSelect) CsvNullToken(token string) *FluentSelect {
+	s.qo.Options.CsvNullToken = token
+	return s
+}
+
+// CsvDialect sets the delimiter, quoting, line terminator, header aliases
+// and byte order mark used for csv output.
+func (s *FluentSelect) CsvDialect(dialect CsvDialect) *FluentSelect {
+	s.qo.Options.CsvDialect = dialect
+	return s
+}
+
 func (s *FluentSelect) PanicOnErr(panicOnErr bool) *FluentSelect {
 	s.qi.PanicOnErr = panicOnErr
 	return s
@@ -82,12 +126,69 @@ func (s *FluentSelect) Params(params ...interface{}) *FluentSelect {
 	return s
 }
 
+// NamedParams binds :name or @name statement parameters from a map with string keys
+// or a struct with db tagged fields.  Named parameters are rewritten to the bind
+// format of the store dialect, so statements can be shared across stores.
+func (s *FluentSelect) NamedParams(params interface{}) *FluentSelect {
+	s.qi.NamedParams = params
+	return s
+}
+
+// Where filters the query results with criteria such as Eq("id", 1) or Gt("ts", t).
+// Multiple criteria, and multiple calls to Where, must all match.  Criteria values
+// are bound as statement parameters after any Params or NamedParams.
+func (s *FluentSelect) Where(criteria ...Criterion) *FluentSelect {
+	s.qi.Criteria = append(s.qi.Criteria, criteria...)
+	return s
+}
+
+func (s *FluentSelect) OrderBy(order ...Order) *FluentSelect {
+	s.qi.Order = append(s.qi.Order, order...)
+	return s
+}
+
+func (s *FluentSelect) Limit(limit int) *FluentSelect {
+	s.qi.Limit = limit
+	return s
+}
+
+func (s *FluentSelect) Offset(offset int) *FluentSelect {
+	s.qi.Offset = offset
+	return s
+}
+
+// Page fetches a page of at most size rows.  token is "" for the first page and the
+// NextPage token of the previous page after that.  Pages continue after the OrderBy
+// column values of the last row of the previous page, falling back to an offset when
+// there is no order or a value is null.  The order columns should uniquely identify a row.
+func (s *FluentSelect) Page(size int, token string) *FluentSelect {
+	s.qi.Page = &Page{Size: size, Token: token}
+	return s
+}
+
+// NextPage returns the continuation token for the page following the fetched page,
+// or "" when the fetched page is the last page.
+func (s *FluentSelect) NextPage() string {
+	if s.qi.Page == nil {
+		return ""
+	}
+	return s.qi.Page.Next
+}
+
 func (s *FluentSelect) OutputJson(writer io.Writer) *FluentSelect {
 	s.qo.Writer = writer
 	s.qo.OutputFormat = JSON
 	return s
 }
 
+// OutputNdjson writes each row to writer as a json object on its own line,
+// flushing the writer after each row when it supports flushing.
+func (s *FluentSelect) OutputNdjson(writer io.Writer) *FluentSelect {
+	s.qo.Writer = writer
+	s.qo.OutputFormat = NDJSON
+	return s
+}
+
 func (s *FluentSelect) OutputCsv(writer io.Writer) *FluentSelect {
 	s.qo.Writer = writer
 	s.qo.OutputFormat = CSV
@@ -100,18 +201,25 @@ func (s *FluentSelect) ForEachRow(rf RowFunction) *FluentSelect {
 }
 
 func (s *FluentSelect) Fetch() error {
-	error := s.store.Fetch(s.tx, s.qi, s.qo, s.dest)
+	error := s.store.FetchContext(s.ctx, s.tx, s.qi, s.qo, s.dest)
 	return error
 }
 
 func (s *FluentSelect) FetchRows() (Rows, error) {
-	return s.store.FetchRows(s.tx, s.qi)
+	return s.store.FetchRowsContext(s.ctx, s.tx, s.qi)
+}
+
+// FetchPage fetches a paged select to the configured output and returns the
+// continuation token for the next page.
+func (s *FluentSelect) FetchPage() (string, error) {
+	err := s.Fetch()
+	return s.NextPage(), err
 }
 
 // @deprecated: This method will be removed in the next version.  Use Fetch()
 func (s *FluentSelect) FetchI() (interface{}, error) {
 	dest := s.qi.DataSet.FieldSlice()
-	error := s.store.Fetch(s.tx, s.qi, s.qo, dest)
+	error := s.store.FetchContext(s.ctx, s.tx, s.qi, s.qo, dest)
 	return dest, error
 }
 
@@ -119,12 +227,12 @@ func (s *FluentSelect) FetchI() (interface{}, error) {
 func (s *FluentSelect) FetchJSON() ([]byte, error) {
 	var b bytes.Buffer
 	writer := bufio.NewWriter(&b)
-	err := s.store.GetJSON(writer, s.qi, s.qo.Options)
+	err := s.store.GetJSONContext(s.ctx, s.tx, writer, s.qi, s.qo.Options)
 	writer.Flush()
 	return b.Bytes(), err
 }
 
 // @deprecated: This method will be removed in the next version.  Use Fetch()
 func (s *FluentSelect) FetchCSV() (string, error) {
-	return s.store.GetCSV(s.qi, s.qo.Options)
+	return s.store.GetCSVContext(s.ctx, s.tx, s.qi, s.qo.Options)
 }
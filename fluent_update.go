@@ -0,0 +1,64 @@
+package goquery
+
+import "context"
+
+// FluentUpdate updates records by their dbid tagged field.  The statement is generated
+// from the db and dbid tags unless the DataSet has an "update" command.  The binds of a
+// custom update command must follow the StructToUpdateIArray order: the non id fields in
+// struct order, followed by the id, unless they are :name or @name parameters that are
+// all db tagged fields, which are bound by name from each record.
+type FluentUpdate struct {
+	store      DataStore
+	ctx        context.Context
+	ds         DataSet
+	batch      bool
+	batchSize  int
+	tx         *Tx
+	records    interface{}
+	panicOnErr bool
+}
+
+// Context sets the context used to run the update.
+func (u *FluentUpdate) Context(ctx context.Context) *FluentUpdate {
+	u.ctx = ctx
+	return u
+}
+
+func (u *FluentUpdate) Tx(tx *Tx) *FluentUpdate {
+	u.tx = tx
+	return u
+}
+
+func (u *FluentUpdate) Batch(batch bool) *FluentUpdate {
+	u.batch = batch
+	return u
+}
+
+func (u *FluentUpdate) BatchSize(bs int) *FluentUpdate {
+	u.batchSize = bs
+	return u
+}
+
+// Records accepts a single struct, a pointer to a struct, or a slice of structs.
+// Each record is updated using its dbid tagged field as the key.
+func (u *FluentUpdate) Records(recs interface{}) *FluentUpdate {
+	u.records = recs
+	return u
+}
+
+func (u *FluentUpdate) PanicOnErr(panicOnErr bool) *FluentUpdate {
+	u.panicOnErr = panicOnErr
+	return u
+}
+
+// Execute runs the update and returns the total number of rows affected.
+func (u *FluentUpdate) Execute() (int64, error) {
+	ui := UpdateInput{
+		Dataset:    u.ds,
+		Records:    u.records,
+		Batch:      u.batch,
+		BatchSize:  u.batchSize,
+		PanicOnErr: u.panicOnErr,
+	}
+	return u.store.UpdateRecsContext(u.ctx, u.tx, ui)
+}
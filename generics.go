@@ -0,0 +1,39 @@
+package goquery
+
+// Query runs stmt with params and returns the rows as a slice of T.
+//
+//	spots, err := goquery.Query[FishingSpot](store, "select * from fishing_spots where id>$1", 2)
+func Query[T any](store DataStore, stmt string, params ...any) ([]T, error) {
+	return Fetch[T](store.Select(stmt).Params(params...))
+}
+
+// Get runs stmt with params and returns the first row as a T.  T can be a struct or,
+// for single column queries, a scalar type.
+func Get[T any](store DataStore, stmt string, params ...any) (T, error) {
+	return FetchOne[T](store.Select(stmt).Params(params...))
+}
+
+// Fetch runs a configured select and returns the rows as a slice of T.
+//
+//	spots, err := goquery.Fetch[FishingSpot](store.Select().DataSet(&ds).StatementKey("by-id").Params(1))
+func Fetch[T any](s *FluentSelect) ([]T, error) {
+	dest := []T{}
+	err := s.Dest(&dest).Fetch()
+	return dest, err
+}
+
+// FetchPage runs a paged select and returns the page rows as a slice of T along with the
+// continuation token for the next page.
+//
+//	spots, next, err := goquery.FetchPage[FishingSpot](store.Select("select * from fishing_spots").OrderBy(goquery.Asc("id")).Page(50, token))
+func FetchPage[T any](s *FluentSelect) ([]T, string, error) {
+	dest, err := Fetch[T](s)
+	return dest, s.NextPage(), err
+}
+
+// FetchOne runs a configured select and returns the first row as a T.
+func FetchOne[T any](s *FluentSelect) (T, error) {
+	var dest T
+	err := s.Dest(&dest).Fetch()
+	return dest, err
+}
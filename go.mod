@@ -1,4 +1,4 @@
-module github.com/charles-p-howe/goquery
+module github.com/charles-p-howe/goquery/v2
 
 go 1.18
 
@@ -28,5 +28,3 @@ require (
 	golang.org/x/text v0.3.6 // indirect
 	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
-
-replace github.com/usace/goquery => github.com/charles-p-howe/goquery latest
@@ -0,0 +1,118 @@
+package goquery
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Identifier is a table or column name applied to a statement with FluentSelect.Apply.
+// Identifiers are validated and quoted in the store dialect before they are written
+// into the statement, so names taken from configuration or requests can be applied
+// without the risk of sql injection.  Names must be plain or dot qualified identifiers.
+type Identifier struct {
+	Name string
+	//Allowed restricts Name to one of the listed identifiers
+	Allowed []string
+	//FromDataSet restricts Name to the entity or the db tagged fields of the query DataSet
+	FromDataSet bool
+}
+
+// Ident returns an identifier for name.  When allowed names are given, name must match one of them.
+func Ident(name string, allowed ...string) Identifier {
+	return Identifier{Name: name, Allowed: allowed}
+}
+
+// DataSetIdent returns an identifier for name that must match the entity or one of the db
+// tagged fields of the query DataSet.
+func DataSetIdent(name string) Identifier {
+	return Identifier{Name: name, FromDataSet: true}
+}
+
+// quoteIdentifier quotes each part of a dot qualified name, folding the case of each part
+// so the quoted name refers to the same object as the unquoted name.
+func quoteIdentifier(name string, fold func(string) string) string {
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		parts[i] = `"` + fold(part) + `"`
+	}
+	return strings.Join(parts, ".")
+}
+
+// applyIdentifiers replaces the Identifier values in appends with the identifier quoted
+// in the store dialect.  Other values are returned unchanged.
+func applyIdentifiers(appends []interface{}, ds DataSet, dest interface{}, dialect DbDialect) ([]interface{}, error) {
+	var applied []interface{}
+	var dsNames map[string]bool
+	for i, val := range appends {
+		id, ok := val.(Identifier)
+		if !ok {
+			continue
+		}
+		if applied == nil {
+			applied = make([]interface{}, len(appends))
+			copy(applied, appends)
+		}
+		if err := validIdentifier(id.Name); err != nil {
+			return nil, err
+		}
+		if len(id.Allowed) > 0 && !containsFold(id.Allowed, id.Name) {
+			return nil, fmt.Errorf("identifier %q is not allowed", id.Name)
+		}
+		if id.FromDataSet {
+			if dsNames == nil {
+				var err error
+				if dsNames, err = dataSetIdentifiers(ds, dest); err != nil {
+					return nil, err
+				}
+			}
+			if !dsNames[strings.ToLower(id.Name)] {
+				return nil, fmt.Errorf("identifier %q is not a field of the dataset", id.Name)
+			}
+		}
+		if dialect.QuoteIdentifier != nil {
+			applied[i] = dialect.QuoteIdentifier(id.Name)
+		} else {
+			applied[i] = id.Name
+		}
+	}
+	if applied == nil {
+		return appends, nil
+	}
+	return applied, nil
+}
+
+// dataSetIdentifiers returns the lower case entity and db tagged field names of ds.  The
+// fields are read from the DataSet fields or, when those are not set, from dest.
+func dataSetIdentifiers(ds DataSet, dest interface{}) (map[string]bool, error) {
+	if ds == nil {
+		return nil, errors.New("missing dataset for dataset identifiers")
+	}
+	names := map[string]bool{strings.ToLower(ds.Entity()): true}
+	var typ reflect.Type
+	switch {
+	case ds.Fields() != nil:
+		typ = reflect.TypeOf(ds.Fields())
+	case dest != nil:
+		typ = reflect.TypeOf(dest)
+	}
+	for typ != nil && (typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice) {
+		typ = typ.Elem()
+	}
+	if typ != nil && typ.Kind() == reflect.Struct {
+		for tag := range dbFieldIndexes(typ) {
+			names[strings.ToLower(tag)] = true
+		}
+	}
+	return names, nil
+}
+
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
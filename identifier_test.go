@@ -0,0 +1,67 @@
+package goquery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyIdentifiers(t *testing.T) {
+	type spot struct {
+		ID       int32   `db:"id"`
+		Location *string `db:"location"`
+	}
+	ds := &TableDataSet{Name: "fishing_spots", Schema: "lakes", TableFields: spot{}}
+
+	tests := []struct {
+		name    string
+		dialect DbDialect
+		appends []interface{}
+		want    []interface{}
+	}{
+		{
+			name:    "pg",
+			dialect: pgDialect,
+			appends: []interface{}{DataSetIdent("Lakes.Fishing_Spots"), Ident("Location", "id", "location"), 10},
+			want:    []interface{}{`"lakes"."fishing_spots"`, `"location"`, 10},
+		},
+		{
+			name:    "oracle",
+			dialect: oracleDialect,
+			appends: []interface{}{Ident("lakes.fishing_spots"), DataSetIdent("id")},
+			want:    []interface{}{`"LAKES"."FISHING_SPOTS"`, `"ID"`},
+		},
+		{
+			name:    "none",
+			dialect: pgDialect,
+			appends: []interface{}{"raw"},
+			want:    []interface{}{"raw"},
+		},
+	}
+	for _, test := range tests {
+		got, err := applyIdentifiers(test.appends, ds, nil, test.dialect)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: Got %v want %v", test.name, got, test.want)
+		}
+	}
+
+	invalid := []Identifier{
+		Ident(`id"; drop table fishing_spots; --`),
+		Ident("id desc"),
+		Ident(""),
+		Ident("name", "id", "location"),
+		DataSetIdent("name"),
+	}
+	for _, id := range invalid {
+		if _, err := applyIdentifiers([]interface{}{id}, ds, nil, pgDialect); err == nil {
+			t.Errorf("Expected an error applying identifier %q", id.Name)
+		}
+	}
+
+	got, err := applyIdentifiers([]interface{}{DataSetIdent("location")}, &TableDataSet{Name: "t"}, &[]spot{}, pgDialect)
+	if err != nil || got[0] != `"location"` {
+		t.Errorf("Failed to validate a dataset identifier against the dest fields: %v %v", got, err)
+	}
+}
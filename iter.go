@@ -0,0 +1,41 @@
+//go:build go1.23
+
+package goquery
+
+import "iter"
+
+// Iter runs a configured select and returns an iterator over the rows scanned into T.
+// Rows are read as the loop advances and are closed when the loop ends, including on
+// break.  A query or scan error is yielded once with the zero value of T and ends the loop.
+//
+//	for spot, err := range goquery.Iter[FishingSpot](store.Select("select * from fishing_spots")) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func Iter[T any](s *FluentSelect) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		rows, err := s.FetchRows()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var rec T
+			err = rows.ScanStruct(&rec)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(rec, nil) {
+				return
+			}
+		}
+		if err = rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
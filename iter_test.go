@@ -0,0 +1,54 @@
+//go:build go1.23
+
+package goquery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPgxIter(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	var ids []int32
+	for spot, err := range Iter[FishingSpot](store.Select("select * from fishing_spots order by id")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, spot.ID)
+		if spot.ID == 2 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(ids, []int32{1, 2}) {
+		t.Errorf("Failed Iter Test: Got %v want [1 2]", ids)
+	}
+
+	var iterErr error
+	for _, err := range Iter[FishingSpot](store.Select("select * from missing_table")) {
+		iterErr = err
+	}
+	if iterErr == nil {
+		t.Error("Failed Iter Test: expected a query error")
+	}
+}
+
+func TestSqlxIter(t *testing.T) {
+	store := sqlxsetup(t)
+	defer sqlxteardown(store, t)
+
+	count := 0
+	for spot, err := range Iter[FishingSpot](store.Select("select * from fishing_spots order by id")) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		count++
+		if spot.ID != int32(count) {
+			t.Errorf("Failed Iter Test: Got id %d want %d", spot.ID, count)
+		}
+	}
+	if count != 4 {
+		t.Errorf("Failed Iter Test: Got %d rows want 4", count)
+	}
+}
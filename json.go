@@ -1,13 +1,17 @@
 package goquery
 
 import (
+	"bytes"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stoewer/go-strcase"
 )
 
@@ -99,23 +103,115 @@ var str string
 var strType = reflect.TypeOf(str)
 var dte time.Time
 var dateType = reflect.TypeOf(dte)
+var bytesType = reflect.TypeOf([]byte(nil))
+var uuidType = reflect.TypeOf(uuid.UUID{})
 
+var newline []byte = []byte("\n")
+
+// RowsToJSON writes rows as json objects, wrapped in an array when isArray is true.
 func RowsToJSON(builder io.Writer, rows Rows, toCamelCase bool, isArray bool, dateFormat string, omitNull bool) error {
+	options := OutputOptions{
+		ToCamelCase: toCamelCase,
+		IsArray:     isArray,
+		DateFormat:  dateFormat,
+		OmitNull:    omitNull,
+	}
+	return WriteJSON(builder, rows, options)
+}
+
+// WriteJSON writes rows as json objects, wrapped in an array when options.IsArray is true.
+// Objects are nested and grouped according to options.JsonNested and options.JsonGroupBy.
+func WriteJSON(writer io.Writer, rows Rows, options OutputOptions) error {
+	jw, err := newJsonRowWriter(rows, options)
+	if err != nil {
+		return err
+	}
+
+	count := 0
+	if options.IsArray {
+		if _, err = writer.Write(openarray); err != nil {
+			return err
+		}
+	}
+	err = jw.writeObjects(func(obj []byte) error {
+		if count > 0 {
+			if _, err := writer.Write(comma); err != nil {
+				return err
+			}
+		}
+		count++
+		_, err := writer.Write(obj)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if options.IsArray {
+		_, err = writer.Write(closearray)
+	}
+	return err
+}
+
+// WriteNDJSON writes rows as newline delimited json, one object per line.  Each line
+// is written in a single Write call and the writer is flushed after each line when
+// it has a Flush method, such as a bufio.Writer or an http.ResponseWriter.
+func WriteNDJSON(writer io.Writer, rows Rows, options OutputOptions) error {
+	jw, err := newJsonRowWriter(rows, options)
+	if err != nil {
+		return err
+	}
+	var line bytes.Buffer
+	return jw.writeObjects(func(obj []byte) error {
+		line.Reset()
+		line.Write(obj)
+		line.Write(newline)
+		if _, err := writer.Write(line.Bytes()); err != nil {
+			return err
+		}
+		return flushWriter(writer)
+	})
+}
+
+func flushWriter(writer io.Writer) error {
+	switch w := writer.(type) {
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Flush() }:
+		w.Flush()
+	}
+	return nil
+}
+
+// jsonRowWriter scans rows into json marshalable values and writes them as json objects.
+type jsonRowWriter struct {
+	rows    Rows
+	paths   [][]string
+	types   []reflect.Type
+	values  []interface{}
+	options OutputOptions
+	nested  bool
+	//grouping state.  keyCol is the JsonGroupBy column and childCols
+	//holds the index into JsonChildren of each child column or -1
+	keyCol    int
+	childCols []int
+	children  []string
+}
+
+func newJsonRowWriter(rows Rows, options OutputOptions) (*jsonRowWriter, error) {
 	columns, err := rows.Columns()
 	if err != nil {
-		return fmt.Errorf("Column error: %v", err)
+		return nil, fmt.Errorf("Column error: %v", err)
 	}
 
 	tt, err := rows.ColumnTypes()
 	if err != nil {
-		return fmt.Errorf("Column type error: %v", err)
+		return nil, fmt.Errorf("Column type error: %v", err)
 	}
 
 	types := make([]reflect.Type, len(tt))
 	for i, tp := range tt {
-		//st := tp.ScanType()
 		if tp == nil {
-			return fmt.Errorf("Scantype is null for column: %v", err)
+			return nil, fmt.Errorf("Scantype is null for column: %s", columns[i])
 		}
 		switch tp {
 		case strType, nullStringType:
@@ -129,59 +225,349 @@ func RowsToJSON(builder io.Writer, rows Rows, toCamelCase bool, isArray bool, da
 		case dateType, nullTimeType:
 			types[i] = jsonNullTimeType
 		default:
-			types[i] = tp
+			//scan into a pointer to the column type so null values can be detected
+			types[i] = reflect.PtrTo(tp)
 		}
 	}
 
-	values := make([]interface{}, len(tt))
-	//var builder strings.Builder
-	count := 0
+	jw := jsonRowWriter{
+		rows:    rows,
+		paths:   make([][]string, len(columns)),
+		types:   types,
+		values:  make([]interface{}, len(tt)),
+		options: options,
+		nested:  options.JsonNested || options.JsonGroupBy != "",
+		keyCol:  -1,
+	}
 
-	if isArray {
-		builder.Write(openarray)
+	for i, col := range columns {
+		path := []string{col}
+		if jw.nested {
+			path = strings.Split(col, ".")
+		}
+		if options.ToCamelCase {
+			for p := range path {
+				path[p] = strcase.LowerCamelCase(path[p])
+			}
+		}
+		jw.paths[i] = path
 	}
-	for rows.Next() {
-		if count > 0 {
-			builder.Write(comma)
-		}
-		builder.Write(openobj)
-		for i := range values {
-			nv := reflect.New(types[i]) //.Interface()
-			if types[i] == jsonNullTimeType {
-				if dateFormat != "" {
-					nv.Elem().Field(1).SetString(dateFormat)
+
+	if options.JsonGroupBy != "" {
+		jw.childCols = make([]int, len(columns))
+		jw.children = make([]string, len(options.JsonChildren))
+		for i, col := range columns {
+			if col == options.JsonGroupBy {
+				jw.keyCol = i
+			}
+			jw.childCols[i] = -1
+			for c, child := range options.JsonChildren {
+				if strings.HasPrefix(col, child+".") {
+					jw.childCols[i] = c
+					jw.children[c] = jw.paths[i][0]
+				}
+			}
+		}
+		if jw.keyCol < 0 {
+			return nil, fmt.Errorf("json group by column %s is not in the query results", options.JsonGroupBy)
+		}
+		//a parent column cannot share its name with a child array
+		for i, col := range columns {
+			if jw.childCols[i] >= 0 {
+				continue
+			}
+			for _, child := range jw.children {
+				if child != "" && jw.paths[i][0] == child {
+					return nil, fmt.Errorf("json column %s conflicts with json children %s", col, child)
 				}
 			}
-			values[i] = nv.Interface()
 		}
-		err = rows.Scan(values...)
+	}
+	return &jw, nil
+}
+
+// writeObjects writes each row, or each group of rows when grouping, as a json object to emit.
+func (jw *jsonRowWriter) writeObjects(emit func(obj []byte) error) error {
+	var group *jsonNode
+	var groupKey []byte
+	for jw.rows.Next() {
+		err := jw.scan()
+		if err != nil {
+			return err
+		}
+		if jw.keyCol < 0 {
+			obj, err := jw.object()
+			if err != nil {
+				return err
+			}
+			if err = emit(obj); err != nil {
+				return err
+			}
+			continue
+		}
+		key, err := jsonMarshal(jw.values[jw.keyCol])
 		if err != nil {
-			return fmt.Errorf("failed to scan values: %v", err)
+			return err
 		}
-		for i, v := range values {
-			jsonb, err := json.Marshal(v)
+		if group != nil && bytes.Equal(key, groupKey) {
+			err = jw.addChildren(group)
 			if err != nil {
 				return err
 			}
-			jsons := string(jsonb)
-			if omitNull && jsons == "null" {
+			continue
+		}
+		if group != nil {
+			if err = jw.emitNode(group, emit); err != nil {
+				return err
+			}
+		}
+		group, err = jw.groupNode()
+		if err != nil {
+			return err
+		}
+		groupKey = key
+	}
+	if err := jw.rows.Err(); err != nil {
+		return err
+	}
+	if group != nil {
+		return jw.emitNode(group, emit)
+	}
+	return nil
+}
+
+func (jw *jsonRowWriter) emitNode(node *jsonNode, emit func(obj []byte) error) error {
+	obj, err := json.Marshal(node)
+	if err != nil {
+		return err
+	}
+	return emit(obj)
+}
+
+// jsonMarshal marshals a scanned value.  Nil pointers are null and values that are
+// driver.Valuers but not json.Marshalers, such as the sql.Null types, are marshaled
+// as their driver value.
+func jsonMarshal(v interface{}) ([]byte, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return []byte("null"), nil
+		}
+		if _, ok := rv.Interface().(json.Marshaler); ok {
+			break
+		}
+		rv = rv.Elem()
+	}
+	val := rv.Interface()
+	if _, ok := val.(json.Marshaler); !ok {
+		if valuer, ok := val.(driver.Valuer); ok {
+			dv, err := valuer.Value()
+			if err != nil {
+				return nil, err
+			}
+			return json.Marshal(dv)
+		}
+	}
+	return json.Marshal(val)
+}
+
+// scan reads the current row into new values.
+func (jw *jsonRowWriter) scan() error {
+	for i := range jw.values {
+		nv := reflect.New(jw.types[i])
+		if jw.types[i] == jsonNullTimeType {
+			if jw.options.DateFormat != "" {
+				nv.Elem().Field(1).SetString(jw.options.DateFormat)
+			}
+		}
+		jw.values[i] = nv.Interface()
+	}
+	err := jw.rows.Scan(jw.values...)
+	if err != nil {
+		return fmt.Errorf("failed to scan values: %v", err)
+	}
+	return nil
+}
+
+// value returns the json for column i, or nil if it is null and nulls are omitted.
+func (jw *jsonRowWriter) value(i int) (json.RawMessage, error) {
+	jsonb, err := jsonMarshal(jw.values[i])
+	if err != nil {
+		return nil, err
+	}
+	if jw.options.OmitNull && string(jsonb) == "null" {
+		return nil, nil
+	}
+	return jsonb, nil
+}
+
+// object returns the scanned row as a json object.
+func (jw *jsonRowWriter) object() ([]byte, error) {
+	if jw.nested {
+		node := newJsonNode()
+		for i := range jw.values {
+			jsonb, err := jw.value(i)
+			if err != nil {
+				return nil, err
+			}
+			if jsonb == nil {
 				continue
 			}
-			if i > 0 {
-				builder.Write(comma)
+			if err = node.set(jw.paths[i], jsonb); err != nil {
+				return nil, err
 			}
-			fieldName := columns[i]
-			if toCamelCase {
-				fieldName = strcase.LowerCamelCase(fieldName)
+		}
+		return json.Marshal(node)
+	}
+
+	var obj bytes.Buffer
+	obj.Write(openobj)
+	written := 0
+	for i := range jw.values {
+		jsonb, err := jw.value(i)
+		if err != nil {
+			return nil, err
+		}
+		if jsonb == nil {
+			continue
+		}
+		if written > 0 {
+			obj.Write(comma)
+		}
+		fieldName, err := json.Marshal(jw.paths[i][0])
+		if err != nil {
+			return nil, err
+		}
+		obj.Write(fieldName)
+		obj.WriteByte(':')
+		obj.Write(jsonb)
+		written++
+	}
+	obj.Write(closeobj)
+	return obj.Bytes(), nil
+}
+
+// groupNode starts a group from the scanned row.  Parent columns are set on the group
+// and child columns are added to the group child arrays.
+func (jw *jsonRowWriter) groupNode() (*jsonNode, error) {
+	node := newJsonNode()
+	for i := range jw.values {
+		if c := jw.childCols[i]; c >= 0 {
+			if _, ok := node.vals[jw.children[c]]; !ok {
+				node.add(jw.children[c], []*jsonNode{})
 			}
-			builder.Write([]byte(fmt.Sprintf(`"%s":%s`, fieldName, jsons)))
+			continue
+		}
+		jsonb, err := jw.value(i)
+		if err != nil {
+			return nil, err
+		}
+		if jsonb == nil {
+			continue
+		}
+		if err = node.set(jw.paths[i], jsonb); err != nil {
+			return nil, err
 		}
-		builder.Write(closeobj)
-		count++
 	}
+	return node, jw.addChildren(node)
+}
 
-	if isArray {
-		builder.Write(closearray)
+// addChildren appends the child objects in the scanned row to the group child arrays.
+// Children whose columns are all null, such as unmatched rows of an outer join, are skipped.
+func (jw *jsonRowWriter) addChildren(group *jsonNode) error {
+	children := make([]*jsonNode, len(jw.children))
+	found := make([]bool, len(jw.children))
+	for i := range jw.values {
+		c := jw.childCols[i]
+		if c < 0 {
+			continue
+		}
+		jsonb, err := jsonMarshal(jw.values[i])
+		if err != nil {
+			return err
+		}
+		isNull := string(jsonb) == "null"
+		found[c] = found[c] || !isNull
+		if isNull && jw.options.OmitNull {
+			continue
+		}
+		if children[c] == nil {
+			children[c] = newJsonNode()
+		}
+		if err = children[c].set(jw.paths[i][1:], jsonb); err != nil {
+			return err
+		}
+	}
+	for c, child := range children {
+		if !found[c] {
+			continue
+		}
+		name := jw.children[c]
+		group.vals[name] = append(group.vals[name].([]*jsonNode), child)
 	}
 	return nil
 }
+
+// jsonNode is a json object that keeps its fields in insertion order.  Values are
+// json.RawMessage, *jsonNode or []*jsonNode.
+type jsonNode struct {
+	keys []string
+	vals map[string]interface{}
+}
+
+func newJsonNode() *jsonNode {
+	return &jsonNode{vals: make(map[string]interface{})}
+}
+
+func (n *jsonNode) add(key string, val interface{}) {
+	n.keys = append(n.keys, key)
+	n.vals[key] = val
+}
+
+// set sets the value at path, creating nested objects as needed.
+func (n *jsonNode) set(path []string, val json.RawMessage) error {
+	node := n
+	for _, key := range path[:len(path)-1] {
+		child, ok := node.vals[key]
+		if !ok {
+			c := newJsonNode()
+			node.add(key, c)
+			node = c
+			continue
+		}
+		c, ok := child.(*jsonNode)
+		if !ok {
+			return fmt.Errorf("json field %s is both a value and an object", key)
+		}
+		node = c
+	}
+	leaf := path[len(path)-1]
+	if _, ok := node.vals[leaf]; ok {
+		return fmt.Errorf("duplicate json field %s", strings.Join(path, "."))
+	}
+	node.add(leaf, val)
+	return nil
+}
+
+func (n *jsonNode) MarshalJSON() ([]byte, error) {
+	var obj bytes.Buffer
+	obj.Write(openobj)
+	for i, key := range n.keys {
+		if i > 0 {
+			obj.Write(comma)
+		}
+		keyb, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		valb, err := json.Marshal(n.vals[key])
+		if err != nil {
+			return nil, err
+		}
+		obj.Write(keyb)
+		obj.WriteByte(':')
+		obj.Write(valb)
+	}
+	obj.Write(closeobj)
+	return obj.Bytes(), nil
+}
@@ -0,0 +1,254 @@
+package goquery
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgtype"
+)
+
+func TestWriteNDJSON(t *testing.T) {
+	want := `{"id":1,"locationName":"Alpine Frove","visited":"2021-05-01"}
+{"id":2,"locationName":"Rivertown, \"East\""}
+{"id":3}
+`
+	var builder strings.Builder
+	err := WriteNDJSON(&builder, fishingSpotRows(), OutputOptions{
+		ToCamelCase: true,
+		DateFormat:  "2006-01-02",
+		OmitNull:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != want {
+		t.Errorf("Got %s want %s", builder.String(), want)
+	}
+}
+
+func TestRowsToJSONOmitNull(t *testing.T) {
+	rows := fishingSpotRows()
+	rows.columns = []string{"location_name", "id"}
+	rows.types[0], rows.types[1] = rows.types[1], rows.types[0]
+	rows.types = rows.types[:2]
+	for i, row := range rows.data {
+		rows.data[i] = []interface{}{row[1], row[0]}
+	}
+	want := `[{"location_name":"Alpine Frove","id":1},{"location_name":"Rivertown, \"East\"","id":2},{"id":3}]`
+	var builder strings.Builder
+	err := RowsToJSON(&builder, rows, false, true, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != want {
+		t.Errorf("Got %s want %s", builder.String(), want)
+	}
+}
+
+func ownerSpotRows() *memRows {
+	return &memRows{
+		columns: []string{"id", "owner.name", "owner.email", "spots.id", "spots.location_name"},
+		types: []reflect.Type{
+			reflect.TypeOf(int32(0)),
+			reflect.TypeOf(""),
+			reflect.TypeOf(""),
+			reflect.TypeOf(int32(0)),
+			reflect.TypeOf(""),
+		},
+		data: [][]interface{}{
+			{int32(1), "Ann", "ann@example.com", int32(10), "Rivertown"},
+			{int32(1), "Ann", "ann@example.com", int32(11), nil},
+			{int32(2), "Bob", nil, nil, nil},
+		},
+	}
+}
+
+func TestWriteJSONNested(t *testing.T) {
+	want := `[{"id":1,"owner":{"name":"Ann","email":"ann@example.com"},"spots":{"id":10,"locationName":"Rivertown"}},` +
+		`{"id":1,"owner":{"name":"Ann","email":"ann@example.com"},"spots":{"id":11}},` +
+		`{"id":2,"owner":{"name":"Bob"}}]`
+	var builder strings.Builder
+	err := WriteJSON(&builder, ownerSpotRows(), OutputOptions{
+		ToCamelCase: true,
+		IsArray:     true,
+		OmitNull:    true,
+		JsonNested:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != want {
+		t.Errorf("Got %s want %s", builder.String(), want)
+	}
+}
+
+func TestWriteJSONGroupBy(t *testing.T) {
+	want := `{"id":1,"owner":{"name":"Ann","email":"ann@example.com"},"spots":[{"id":10,"location_name":"Rivertown"},{"id":11,"location_name":null}]}
+{"id":2,"owner":{"name":"Bob","email":null},"spots":[]}
+`
+	var builder strings.Builder
+	err := WriteNDJSON(&builder, ownerSpotRows(), OutputOptions{
+		JsonGroupBy:  "id",
+		JsonChildren: []string{"spots"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != want {
+		t.Errorf("Got %s want %s", builder.String(), want)
+	}
+}
+
+func TestWriteJSONGroupByConflict(t *testing.T) {
+	rows := &memRows{
+		columns: []string{"id", "spots", "spots.id"},
+		types:   []reflect.Type{reflect.TypeOf(int32(0)), reflect.TypeOf(""), reflect.TypeOf(int32(0))},
+		data:    [][]interface{}{{int32(1), "two", int32(10)}},
+	}
+	var builder strings.Builder
+	err := WriteNDJSON(&builder, rows, OutputOptions{
+		JsonGroupBy:  "id",
+		JsonChildren: []string{"spots"},
+	})
+	if err == nil {
+		t.Errorf("Expected an error for a column that conflicts with the json children.  Got %s", builder.String())
+	}
+}
+
+func TestWriteJSONTypes(t *testing.T) {
+	one := int32(1)
+	rows := &memRows{
+		columns: []string{"b", "i16", "f32", "doc", "list", "nb", "bytes"},
+		types: []reflect.Type{
+			reflect.TypeOf(false),
+			reflect.TypeOf(int16(0)),
+			reflect.TypeOf(float32(0)),
+			reflect.TypeOf(json.RawMessage(nil)),
+			reflect.TypeOf([]*int32(nil)),
+			reflect.TypeOf(sql.NullBool{}),
+			reflect.TypeOf([]byte(nil)),
+		},
+		data: [][]interface{}{
+			{true, int16(7), float32(1.5), json.RawMessage(`{"name": "jack"}`), []*int32{&one, nil}, sql.NullBool{Bool: true, Valid: true}, []byte("hi")},
+			{nil, nil, nil, nil, nil, nil, nil},
+		},
+	}
+	want := `[{"b":true,"i16":7,"f32":1.5,"doc":{"name":"jack"},"list":[1,null],"nb":true,"bytes":"aGk="},` +
+		`{"b":null,"i16":null,"f32":null,"doc":null,"list":null,"nb":null,"bytes":null}]`
+	var builder strings.Builder
+	err := WriteJSON(&builder, rows, OutputOptions{IsArray: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if builder.String() != want {
+		t.Errorf("Got %s want %s", builder.String(), want)
+	}
+}
+
+func TestPgxNumericJSON(t *testing.T) {
+	big1, _ := new(big.Int).SetString("123456789012345678900100", 10)
+	tests := []struct {
+		n    pgtype.Numeric
+		want string
+	}{
+		{pgtype.Numeric{Int: big1, Exp: -4, Status: pgtype.Present}, "12345678901234567890.0100"},
+		{pgtype.Numeric{Int: big.NewInt(-5), Exp: -3, Status: pgtype.Present}, "-0.005"},
+		{pgtype.Numeric{Int: big.NewInt(-125), Exp: -2, Status: pgtype.Present}, "-1.25"},
+		{pgtype.Numeric{Int: big.NewInt(12), Exp: 2, Status: pgtype.Present}, "1200"},
+		{pgtype.Numeric{Int: big.NewInt(0), Exp: -2, Status: pgtype.Present}, "0.00"},
+		{pgtype.Numeric{Status: pgtype.Present, NaN: true}, `"NaN"`},
+		{pgtype.Numeric{Status: pgtype.Null}, "null"},
+	}
+	for _, test := range tests {
+		b, err := json.Marshal(PgxNumeric{test.n})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.want {
+			t.Errorf("Got %s want %s", b, test.want)
+		}
+	}
+}
+
+type jsonImportRec struct {
+	ID       int32          `db:"id"`
+	Location *string        `db:"location_name"`
+	Visited  time.Time      `db:"visited"`
+	Notes    sql.NullString `db:"notes"`
+	Attrs    JsonAttr       `db:"attrs"`
+}
+
+func TestParseJsonRecord(t *testing.T) {
+	fi := FluentImport{camelCase: true, dateFormat: "02-Jan-2006", headerMap: map[string]string{"extra": "-"}}
+	typ := reflect.TypeOf(jsonImportRec{})
+	fields := fi.jsonFields(typ)
+
+	var obj map[string]json.RawMessage
+	err := json.Unmarshal([]byte(`{"id":5,"locationName":"Rivertown","visited":"01-May-2021",`+
+		`"notes":"a note","attrs":{"name":"jack","age":8},"extra":true}`), &obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := reflect.New(typ).Elem()
+	err = fi.setJsonFields(rec, fields, obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	location := "Rivertown"
+	want := jsonImportRec{
+		ID:       5,
+		Location: &location,
+		Visited:  time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC),
+		Notes:    sql.NullString{String: "a note", Valid: true},
+		Attrs:    JsonAttr{"jack", 8},
+	}
+	if !reflect.DeepEqual(rec.Interface(), want) {
+		t.Errorf("Got %v want %v", rec.Interface(), want)
+	}
+
+	obj = map[string]json.RawMessage{"location_name": json.RawMessage(`"x"`)}
+	if err = fi.setJsonFields(rec, fields, obj); err == nil {
+		t.Error("expected an error for a field that is not camel case")
+	}
+}
+
+func TestFromJsonLines(t *testing.T) {
+	ds := TableDataSet{Name: "json_import", TableFields: jsonImportRec{}}
+	tests := []struct {
+		input string
+		want  []int
+	}{
+		{"\n{\"id\":1,\"bad\":1}\n\n[1]\n\"x\"\n", []int{2, 4, 5}},
+		{"[\n{\"id\":1,\"bad\":1},\n  {\"bad\":2}\n]", []int{2, 3}},
+	}
+	for _, test := range tests {
+		fi := FluentImport{ds: &ds, skipBadRows: true}
+		result, err := fi.FromJson(strings.NewReader(test.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := []int{}
+		for _, skipped := range result.Skipped {
+			lines = append(lines, skipped.Line)
+		}
+		if !reflect.DeepEqual(lines, test.want) {
+			t.Errorf("Got skipped lines %v want %v", lines, test.want)
+		}
+	}
+
+	fi := FluentImport{ds: &ds, skipBadRows: true}
+	result, err := fi.FromJson(strings.NewReader("{\"id\":\"a\"}\n\n{bad}\n{\"id\":2}\n"))
+	var ie *ImportError
+	if !errors.As(err, &ie) || ie.Line != 3 {
+		t.Errorf("Got %v want a syntax error on line 3", err)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0].Line != 1 {
+		t.Errorf("Got skipped %v want line 1", result.Skipped)
+	}
+}
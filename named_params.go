@@ -0,0 +1,201 @@
+package goquery
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// NamedArgs holds named parameters passed to the Exec methods.  See Named.
+type NamedArgs struct {
+	params interface{}
+}
+
+// Named passes named parameters to the Exec methods in place of positional parameters.
+// params is a map with string keys or a struct whose db tagged fields supply the values,
+// for example store.Exec(NoTx, "delete from t where id=:id", Named(map[string]any{"id": 1})).
+func Named(params interface{}) NamedArgs {
+	return NamedArgs{params}
+}
+
+// BindNamed rewrites the :name and @name parameters in stmt to the dialect bind format
+// and returns the rewritten statement with the parameter values in bind order.  params
+// is a map with string keys or a struct whose db tagged fields supply the values.
+// Parameters inside quoted strings, quoted identifiers and comments are left alone,
+// as are postgres :: casts.  The postgres dialect only binds :name parameters since
+// @ is a postgres operator.
+func BindNamed(stmt string, params interface{}, dialect DbDialect) (string, []interface{}, error) {
+	lookup, err := namedParamLookup(params)
+	if err != nil {
+		return "", nil, err
+	}
+	bstmt, names := namedBinds(stmt, dialect)
+	args, err := namedValues(names, lookup)
+	if err != nil {
+		return "", nil, err
+	}
+	return bstmt, args, nil
+}
+
+// namedBinds rewrites the named parameters in stmt to the dialect bind format and
+// returns the rewritten statement with the parameter names in bind order.
+func namedBinds(stmt string, dialect DbDialect) (string, []string) {
+	var builder strings.Builder
+	var names []string
+	//@ is an operator in postgres (@@, @>, @) so only :name is a parameter
+	atParams := !strings.HasPrefix(dialect.Bind("p1", 0), "$")
+	prev := rune(0)
+	for i := 0; i < len(stmt); {
+		r, size := utf8.DecodeRuneInString(stmt[i:])
+		if end := skipNonCode(stmt, i); end > i {
+			builder.WriteString(stmt[i:end])
+			i = end
+			prev = ' '
+			continue
+		}
+		switch {
+		case r == ':' && strings.HasPrefix(stmt[i:], "::"):
+			builder.WriteString("::")
+			i += 2
+			prev = ':'
+			continue
+		case (r == ':' || (r == '@' && atParams)) && !isIdentRune(prev) && prev != '@':
+			name := paramName(stmt[i+1:])
+			if name != "" {
+				builder.WriteString(dialect.Bind(name, len(names)))
+				names = append(names, name)
+				i += 1 + len(name)
+				prev = 'a'
+				continue
+			}
+		}
+		builder.WriteRune(r)
+		i += size
+		prev = r
+	}
+	return builder.String(), names
+}
+
+func namedValues(names []string, lookup func(name string) (interface{}, bool)) ([]interface{}, error) {
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		val, ok := lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("missing value for named parameter %s", name)
+		}
+		args[i] = val
+	}
+	return args, nil
+}
+
+// namedCommand rewrites a dataset command to the dialect bind format when every one of
+// its :name or @name parameters is a db tagged field of the dataset, and returns the
+// parameter names in bind order.  Otherwise the command is returned unchanged with nil
+// names and is bound by position.
+func namedCommand(cmd string, ds DataSet, dialect DbDialect) (string, []string) {
+	if ds.Fields() == nil {
+		return cmd, nil
+	}
+	bstmt, names := namedBinds(cmd, dialect)
+	if len(names) == 0 {
+		return cmd, nil
+	}
+	fields := dbFieldIndexes(reflect.TypeOf(ds.Fields()))
+	for _, name := range names {
+		if _, ok := fields[name]; !ok {
+			return cmd, nil
+		}
+	}
+	return bstmt, names
+}
+
+// recordValues returns the values of the named parameters of a dataset command from rec.
+func recordValues(names []string, rec interface{}) ([]interface{}, error) {
+	lookup, err := namedParamLookup(rec)
+	if err != nil {
+		return nil, err
+	}
+	return namedValues(names, lookup)
+}
+
+// namedParamLookup returns a function that looks up named parameter values in params.
+func namedParamLookup(params interface{}) (func(name string) (interface{}, bool), error) {
+	val := reflect.Indirect(reflect.ValueOf(params))
+	switch val.Kind() {
+	case reflect.Map:
+		if val.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("invalid named parameter type %s.  map keys must be strings", val.Type())
+		}
+		return func(name string) (interface{}, bool) {
+			v := val.MapIndex(reflect.ValueOf(name).Convert(val.Type().Key()))
+			if !v.IsValid() {
+				return nil, false
+			}
+			return v.Interface(), true
+		}, nil
+	case reflect.Struct:
+		fields := dbFieldIndexes(val.Type())
+		return func(name string) (interface{}, bool) {
+			index, ok := fields[name]
+			if !ok {
+				return nil, false
+			}
+			return fieldValue(val.FieldByIndex(index)), true
+		}, nil
+	case reflect.Invalid:
+		return nil, errors.New("named parameters are nil")
+	default:
+		return nil, fmt.Errorf("invalid named parameter type %s.  expected a map or struct", val.Type())
+	}
+}
+
+// skipNonCode returns the index after the quoted string, quoted identifier or comment
+// starting at stmt[i], or i when stmt[i] does not start one.
+func skipNonCode(stmt string, i int) int {
+	switch {
+	case stmt[i] == '\'' || stmt[i] == '"':
+		return skipQuoted(stmt, i, stmt[i])
+	case strings.HasPrefix(stmt[i:], "--"):
+		if end := strings.IndexByte(stmt[i:], '\n'); end >= 0 {
+			return i + end
+		}
+		return len(stmt)
+	case strings.HasPrefix(stmt[i:], "/*"):
+		if end := strings.Index(stmt[i+2:], "*/"); end >= 0 {
+			return i + end + 4
+		}
+		return len(stmt)
+	}
+	return i
+}
+
+// skipQuoted returns the index after the quoted section starting at stmt[start].
+// Doubled quotes are treated as escaped quotes.
+func skipQuoted(stmt string, start int, quote byte) int {
+	for i := start + 1; i < len(stmt); i++ {
+		if stmt[i] == quote {
+			if i+1 < len(stmt) && stmt[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(stmt)
+}
+
+func paramName(s string) string {
+	for i, r := range s {
+		if !isIdentRune(r) || (i == 0 && unicode.IsDigit(r)) {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func isIdentRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,99 @@
+package goquery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBindNamed(t *testing.T) {
+	location := "Rivertown"
+	params := map[string]interface{}{"id": 1, "location": location}
+	stmt := `select id::text, ':skip', "@col" from fishing_spots -- :comment
+		where id=:id and (location=:location or :id=0) /* @x */ and tags[1:2] is not null`
+
+	tests := []struct {
+		dialect DbDialect
+		want    string
+	}{
+		{pgDialect, `select id::text, ':skip', "@col" from fishing_spots -- :comment
+		where id=$1 and (location=$2 or $3=0) /* @x */ and tags[1:2] is not null`},
+		{oracleDialect, `select id::text, ':skip', "@col" from fishing_spots -- :comment
+		where id=:id and (location=:location or :id=0) /* @x */ and tags[1:2] is not null`},
+	}
+	for _, test := range tests {
+		got, args, err := BindNamed(stmt, params, test.dialect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Errorf("Got %s want %s", got, test.want)
+		}
+		if !reflect.DeepEqual(args, []interface{}{1, location, 1}) {
+			t.Errorf("Got args %v want [1 %s 1]", args, location)
+		}
+	}
+
+	args := FishingSpot{ID: 2, Location: &location}
+	_, bound, err := BindNamed("select * from fishing_spots where id=:id and location=:location", args, pgDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(bound, []interface{}{int32(2), location}) {
+		t.Errorf("Got args %v want [2 %s]", bound, location)
+	}
+
+	//postgres full text and containment operators are not parameters
+	fts := "select * from docs where tsv @@ to_tsquery(:location) and tags @> array[:id] and @ :id > 0"
+	got, bound, err := BindNamed(fts, params, pgDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select * from docs where tsv @@ to_tsquery($1) and tags @> array[$2] and @ $3 > 0"; got != want {
+		t.Errorf("Got %s want %s", got, want)
+	}
+	if !reflect.DeepEqual(bound, []interface{}{location, 1, 1}) {
+		t.Errorf("Got args %v want [%s 1 1]", bound, location)
+	}
+
+	got, bound, err = BindNamed("select * from fishing_spots where location=@location and id@@id", params, oracleDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "select * from fishing_spots where location=:location and id@@id"; got != want {
+		t.Errorf("Got %s want %s", got, want)
+	}
+	if !reflect.DeepEqual(bound, []interface{}{location}) {
+		t.Errorf("Got args %v want [%s]", bound, location)
+	}
+
+	if _, _, err = BindNamed("select * from t where id=:missing", params, pgDialect); err == nil {
+		t.Error("expected an error for a missing named parameter")
+	}
+}
+
+func TestNamedCommand(t *testing.T) {
+	ds := TableDataSet{Name: "fishing_spots", TableFields: FishingSpotRec{}}
+	stmt, names := namedCommand("update fishing_spots set location=:location where id=:id", &ds, pgDialect)
+	if stmt != "update fishing_spots set location=$1 where id=$2" || !reflect.DeepEqual(names, []string{"location", "id"}) {
+		t.Errorf("Got %s %v", stmt, names)
+	}
+
+	positional := []string{
+		"update fishing_spots set location=$1 where id=$2",
+		"update fishing_spots set location=:loc where id=:id",
+	}
+	for _, cmd := range positional {
+		if stmt, names := namedCommand(cmd, &ds, oracleDialect); stmt != cmd || names != nil {
+			t.Errorf("Expected %s to be bound by position, got %s %v", cmd, stmt, names)
+		}
+	}
+
+	loc := "Rivertown"
+	vals, err := recordValues(names, &FishingSpotRec{2, &loc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(vals, []interface{}{loc, int32(2)}) {
+		t.Errorf("Got %v want [%s 2]", vals, loc)
+	}
+}
@@ -1,14 +1,81 @@
 package goquery
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
 
 var oracleDialect = DbDialect{
-	TableExistsStmt: `select count(*) from user_tables where table_name=$1`,
+	TableExistsStmt: `select count(*) from all_tables where owner=upper(coalesce(:1,user)) and table_name=upper(:2)`,
 	Bind: func(field string, i int) string {
 		return fmt.Sprintf(":%s", field)
 	},
 	Seq: func(sequence string) string {
-		return fmt.Sprintf("nextval('%s')", sequence)
+		return fmt.Sprintf("%s.nextval", sequence)
+	},
+	Upsert: func(table string, fields []string, binds []string, conflict []string, update []string) (string, error) {
+		if len(conflict) == 0 {
+			return "", errors.New("oracle merge requires conflict columns")
+		}
+		//bound values are selected from dual as the merge source.
+		//sequence expressions are only evaluated when inserting.
+		var source []string
+		bound := make(map[string]bool)
+		values := make([]string, len(fields))
+		for i, field := range fields {
+			if strings.HasPrefix(binds[i], ":") {
+				source = append(source, fmt.Sprintf("%s %s", binds[i], field))
+				values[i] = fmt.Sprintf("s.%s", field)
+				bound[field] = true
+			} else {
+				values[i] = binds[i]
+			}
+		}
+		for _, col := range conflict {
+			if !bound[col] {
+				return "", fmt.Errorf("invalid merge conflict column %q.  oracle conflict columns must be bound fields, not generated ids", col)
+			}
+		}
+		on := make([]string, len(conflict))
+		for i, col := range conflict {
+			on[i] = fmt.Sprintf("d.%s = s.%s", col, col)
+		}
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("merge into %s d using (select %s from dual) s on (%s)",
+			table, strings.Join(source, ","), strings.Join(on, " and ")))
+		if len(update) > 0 {
+			sets := make([]string, len(update))
+			for i, col := range update {
+				sets[i] = fmt.Sprintf("d.%s = s.%s", col, col)
+			}
+			builder.WriteString(fmt.Sprintf(" when matched then update set %s", strings.Join(sets, ",")))
+		}
+		builder.WriteString(fmt.Sprintf(" when not matched then insert (%s) values (%s)",
+			strings.Join(fields, ","), strings.Join(values, ",")))
+		return builder.String(), nil
+	},
+	Returning: func(idfield string) string {
+		return fmt.Sprintf(" returning %s into :%s", idfield, idfield)
+	},
+	ReturningInto: true,
+	MaxInList:     1000,
+	ColumnType:    oracleColumnType,
+	EmptyList: func(colType string) string {
+		return fmt.Sprintf("select cast(null as %s) from dual where 1=0", colType)
+	},
+	LimitOffset: func(limit int, offset int) string {
+		if limit <= 0 {
+			return fmt.Sprintf("offset %d rows", offset)
+		}
+		return fmt.Sprintf("offset %d rows fetch next %d rows only", offset, limit)
+	},
+	QuoteIdentifier: func(name string) string {
+		return quoteIdentifier(name, strings.ToUpper)
+	},
+	RestartIdentity: func(table string, column string, start int64) string {
+		return fmt.Sprintf("alter table %s modify %s generated by default as identity (start with %d)", table, column, start)
 	},
 	Url: func(config *RdbmsConfig) string {
 		if config.OnInit == "" {
@@ -19,3 +86,44 @@ var oracleDialect = DbDialect{
 			config.Dbuser, config.Dbpass, config.Dbhost, config.Dbport, config.Dbname, config.ExternalLib, config.OnInit, config.DbDriverSettings)
 	},
 }
+
+func oracleColumnType(typ reflect.Type) (string, error) {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ {
+	case dateType, nullTimeType:
+		return "timestamp", nil
+	case uuidType:
+		return "varchar2(36)", nil
+	case bytesType:
+		return "blob", nil
+	case nullStringType:
+		return "varchar2(4000)", nil
+	case nullI32Type:
+		return "number(10)", nil
+	case nullI64Type:
+		return "number(19)", nil
+	case nullF64Type:
+		return "binary_double", nil
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return "varchar2(4000)", nil
+	case reflect.Bool:
+		return "number(1)", nil
+	case reflect.Int8, reflect.Int16:
+		return "number(5)", nil
+	case reflect.Int32:
+		return "number(10)", nil
+	case reflect.Int, reflect.Int64:
+		return "number(19)", nil
+	case reflect.Float32:
+		return "binary_float", nil
+	case reflect.Float64:
+		return "binary_double", nil
+	case reflect.Slice, reflect.Array, reflect.Struct, reflect.Map:
+		return "clob", nil
+	}
+	return "", fmt.Errorf("unsupported column type: %s", typ)
+}
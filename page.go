@@ -0,0 +1,292 @@
+package goquery
+
+import (
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page requests a page of query results.  Token is the continuation token returned for
+// the previous page, or "" for the first page.  Fetching the page sets Next to the token
+// for the following page, or to "" when there are no more rows.
+type Page struct {
+	Size  int
+	Token string
+	Next  string
+}
+
+// pageToken is the decoded continuation token.  Offset is the number of rows on the
+// previous pages.  Keys holds the order column values of the last row of the previous
+// page when the page can be continued with keyset pagination.
+type pageToken struct {
+	Offset  int       `json:"o,omitempty"`
+	Columns []string  `json:"c,omitempty"`
+	Keys    []pageKey `json:"k,omitempty"`
+}
+
+type pageKey struct {
+	Type  string `json:"t"`
+	Value string `json:"v"`
+}
+
+// pageInput returns qi limited to its requested page along with the decoded page token.
+// Pages after the first continue after the keys of the previous page when the token has
+// them, and fall back to skipping the rows of the previous pages when it does not.  One
+// extra row is selected to detect whether there is a following page.
+func pageInput(qi QueryInput) (QueryInput, pageToken, error) {
+	var token pageToken
+	if qi.Page == nil {
+		return qi, token, nil
+	}
+	qi.Page.Next = ""
+	if qi.Page.Size <= 0 {
+		return qi, token, fmt.Errorf("invalid page size: %d", qi.Page.Size)
+	}
+	if qi.Limit > 0 || qi.Offset > 0 {
+		return qi, token, errors.New("a paged query cannot set a limit or offset")
+	}
+	//key columns are read back from the result columns by name
+	for _, o := range qi.Order {
+		if err := validColumn(o.Column); err != nil {
+			return qi, token, err
+		}
+	}
+	if qi.Page.Token != "" {
+		data, err := base64.RawURLEncoding.DecodeString(qi.Page.Token)
+		if err == nil {
+			err = json.Unmarshal(data, &token)
+		}
+		if err != nil || token.Offset < 0 {
+			return qi, token, errors.New("invalid page token")
+		}
+	}
+	if len(token.Keys) > 0 {
+		if !reflect.DeepEqual(token.Columns, pageColumns(qi.Order)) || len(token.Keys) != len(qi.Order) {
+			return qi, token, errors.New("page token does not match the query order")
+		}
+		criterion, err := keysetCriterion(qi.Order, token.Keys)
+		if err != nil {
+			return qi, token, err
+		}
+		qi.Criteria = append(append([]Criterion{}, qi.Criteria...), criterion)
+	} else {
+		qi.Offset = token.Offset
+	}
+	qi.Limit = qi.Page.Size + 1
+	return qi, token, nil
+}
+
+// keysetCriterion matches the rows that come after keys in the query order:
+// (c1 > k1) or (c1 = k1 and c2 > k2) or ...  Null values sort after non null values,
+// as they do by default in postgres and oracle, so they come after any key in ascending
+// order and before any key in descending order.
+func keysetCriterion(order []Order, keys []pageKey) (Criterion, error) {
+	vals := make([]interface{}, len(keys))
+	for i, key := range keys {
+		var err error
+		if vals[i], err = key.value(); err != nil {
+			return nil, err
+		}
+	}
+	after := make([]Criterion, len(order))
+	for i, o := range order {
+		criteria := make([]Criterion, i+1)
+		for j := 0; j < i; j++ {
+			criteria[j] = Eq(order[j].Column, vals[j])
+		}
+		if o.Desc {
+			criteria[i] = Lt(o.Column, vals[i])
+		} else {
+			criteria[i] = Or(Gt(o.Column, vals[i]), IsNull(o.Column))
+		}
+		after[i] = And(criteria...)
+	}
+	return Or(after...), nil
+}
+
+// nextPageToken returns the token for the page following token.  The token uses keyset
+// pagination when the last row keys are known and falls back to an offset otherwise.
+func nextPageToken(token pageToken, size int, order []Order, keys []pageKey) string {
+	next := pageToken{Offset: token.Offset + size}
+	if len(order) > 0 && keys != nil {
+		next.Columns = pageColumns(order)
+		next.Keys = keys
+	}
+	data, _ := json.Marshal(next)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func pageColumns(order []Order) []string {
+	columns := make([]string, len(order))
+	for i, o := range order {
+		columns[i] = o.Column
+		if o.Desc {
+			columns[i] += " desc"
+		}
+	}
+	return columns
+}
+
+// newPageKey encodes a key column value.  ok is false for null values and types that
+// cannot be used as a key.
+func newPageKey(val interface{}) (pageKey, bool) {
+	if valuer, ok := val.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return pageKey{}, false
+		}
+		if _, ok := v.(driver.Valuer); ok {
+			return pageKey{}, false
+		}
+		return newPageKey(v)
+	}
+	if t, ok := val.(time.Time); ok {
+		return pageKey{"time", t.Format(time.RFC3339Nano)}, true
+	}
+	rval := reflect.ValueOf(val)
+	switch rval.Kind() {
+	case reflect.Pointer:
+		if rval.IsNil() {
+			return pageKey{}, false
+		}
+		return newPageKey(rval.Elem().Interface())
+	case reflect.String:
+		return pageKey{"string", rval.String()}, true
+	case reflect.Bool:
+		return pageKey{"bool", strconv.FormatBool(rval.Bool())}, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return pageKey{"int", strconv.FormatInt(rval.Int(), 10)}, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return pageKey{"uint", strconv.FormatUint(rval.Uint(), 10)}, true
+	case reflect.Float32, reflect.Float64:
+		return pageKey{"float", strconv.FormatFloat(rval.Float(), 'g', -1, 64)}, true
+	}
+	return pageKey{}, false
+}
+
+func (pk pageKey) value() (interface{}, error) {
+	var val interface{}
+	var err error
+	switch pk.Type {
+	case "string":
+		val = pk.Value
+	case "time":
+		val, err = time.Parse(time.RFC3339Nano, pk.Value)
+	case "bool":
+		val, err = strconv.ParseBool(pk.Value)
+	case "int":
+		val, err = strconv.ParseInt(pk.Value, 10, 64)
+	case "uint":
+		val, err = strconv.ParseUint(pk.Value, 10, 64)
+	case "float":
+		val, err = strconv.ParseFloat(pk.Value, 64)
+	default:
+		err = fmt.Errorf("unsupported type %q", pk.Type)
+	}
+	if err != nil {
+		return nil, errors.New("invalid page token")
+	}
+	return val, nil
+}
+
+// structPageKeys returns the page keys for the order columns of a struct record,
+// or nil when a key is null or not a db tagged field.
+func structPageKeys(rec reflect.Value, order []Order) []pageKey {
+	rec = reflect.Indirect(rec)
+	if rec.Kind() != reflect.Struct || len(order) == 0 {
+		return nil
+	}
+	fields := dbFieldIndexes(rec.Type())
+	keys := make([]pageKey, len(order))
+	for i, o := range order {
+		index, ok := fields[o.Column]
+		if !ok {
+			return nil
+		}
+		if keys[i], ok = newPageKey(rec.FieldByIndex(index).Interface()); !ok {
+			return nil
+		}
+	}
+	return keys
+}
+
+// pageRows limits Rows to a page and sets the next page token once the page is read.
+// The order column values of each scanned row are kept for the keyset token.
+type pageRows struct {
+	Rows
+	page  *Page
+	token pageToken
+	order []Order
+	keys  []pageKey
+	n     int
+}
+
+func (pr *pageRows) Next() bool {
+	if pr.n >= pr.page.Size {
+		if pr.n == pr.page.Size && pr.Rows.Next() {
+			pr.page.Next = nextPageToken(pr.token, pr.page.Size, pr.order, pr.keys)
+		}
+		pr.n = pr.page.Size + 1
+		return false
+	}
+	if !pr.Rows.Next() {
+		return false
+	}
+	pr.n++
+	return true
+}
+
+func (pr *pageRows) Scan(dest ...interface{}) error {
+	err := pr.Rows.Scan(dest...)
+	if err != nil || len(pr.order) == 0 {
+		return err
+	}
+	pr.keys = nil
+	columns, err := pr.Rows.Columns()
+	if err != nil {
+		return err
+	}
+	keys := make([]pageKey, len(pr.order))
+	for i, o := range pr.order {
+		idx := -1
+		for c, column := range columns {
+			if strings.EqualFold(column, o.Column) {
+				idx = c
+				break
+			}
+		}
+		if idx < 0 || idx >= len(dest) {
+			return nil
+		}
+		var ok bool
+		if keys[i], ok = newPageKey(dest[idx]); !ok {
+			return nil
+		}
+	}
+	pr.keys = keys
+	return nil
+}
+
+func (pr *pageRows) ScanStruct(dest interface{}) error {
+	err := pr.Rows.ScanStruct(dest)
+	if err == nil {
+		pr.keys = structPageKeys(reflect.ValueOf(dest), pr.order)
+	}
+	return err
+}
+
+// pageSlice trims the rows selected into slice to the page size and sets the next page token.
+func pageSlice(slice reflect.Value, page *Page, token pageToken, order []Order) {
+	if slice.Len() <= page.Size {
+		return
+	}
+	keys := structPageKeys(slice.Index(page.Size-1), order)
+	page.Next = nextPageToken(token, page.Size, order, keys)
+	slice.Set(slice.Slice(0, page.Size))
+}
@@ -0,0 +1,92 @@
+package goquery
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPageRows(t *testing.T) {
+	page := &Page{Size: 2}
+	rows := &pageRows{Rows: fishingSpotRows(), page: page, order: []Order{Asc("id")}}
+	var builder strings.Builder
+	err := WriteCSV(&builder, rows, OutputOptions{CsvPrintHeader: true, DateFormat: "02-Jan-2006"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "id,location_name,visited\n1,Alpine Frove,01-May-2021\n2,\"Rivertown, \"\"East\"\"\",\n"
+	if builder.String() != want {
+		t.Errorf("Got %q want %q", builder.String(), want)
+	}
+	if page.Next == "" {
+		t.Fatal("Expected a next page token")
+	}
+
+	qi := QueryInput{Order: []Order{Asc("id")}, Page: &Page{Size: 2, Token: page.Next}}
+	pqi, token, err := pageInput(qi)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Offset != 2 || !reflect.DeepEqual(token.Keys, []pageKey{{"int", "2"}}) {
+		t.Errorf("Got token %v want offset 2 and id key 2", token)
+	}
+	stmt, args, err := applyCriteria("select * from t", nil, pqi, pgDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantStmt := "select * from (select * from t) q where ((((id > $1) or (id is null)))) order by id limit 3 offset 0"
+	if stmt != wantStmt || !reflect.DeepEqual(args, []interface{}{int64(2)}) {
+		t.Errorf("Got %s %v want %s [2]", stmt, args, wantStmt)
+	}
+
+	//the last row of the second page has a null key, so the next page falls back to an offset
+	page = &Page{Size: 1, Token: page.Next}
+	rows = &pageRows{Rows: fishingSpotRows(), page: page, token: token, order: []Order{Asc("visited")}}
+	rows.Rows.(*memRows).i = 1
+	if err = WriteCSV(&builder, rows, OutputOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	_, token, err = pageInput(QueryInput{Order: []Order{Asc("visited")}, Page: &Page{Size: 1, Token: page.Next}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Offset != 3 || token.Keys != nil {
+		t.Errorf("Got token %v want an offset 3 token", token)
+	}
+
+	if _, _, err = pageInput(QueryInput{Order: []Order{Desc("id")}, Page: &Page{Size: 2, Token: qi.Page.Token}}); err == nil {
+		t.Error("Expected an error for a token from a different order")
+	}
+	if _, _, err = pageInput(QueryInput{Page: &Page{Size: 2, Token: "not a token"}}); err == nil {
+		t.Error("Expected an error for an invalid token")
+	}
+	if _, _, err = pageInput(QueryInput{Order: []Order{Asc("t.id")}, Page: &Page{Size: 2}}); err == nil {
+		t.Error("Expected an error for a qualified key column")
+	}
+}
+
+func TestPageKeys(t *testing.T) {
+	ts := time.Date(2021, 5, 1, 12, 30, 0, 5, time.UTC)
+	name := "x"
+	for _, val := range []interface{}{"a", &name, int32(-4), uint8(3), 1.5, true, ts} {
+		key, ok := newPageKey(val)
+		if !ok {
+			t.Errorf("Unable to make a page key for %v", val)
+			continue
+		}
+		got, err := key.value()
+		if err != nil {
+			t.Error(err)
+		}
+		if k, _ := newPageKey(got); k != key {
+			t.Errorf("Got key %v want %v", k, key)
+		}
+	}
+	var null *string
+	for _, val := range []interface{}{nil, null, []byte("x")} {
+		if _, ok := newPageKey(val); ok {
+			t.Errorf("Expected no page key for %v", val)
+		}
+	}
+}
@@ -1,18 +1,61 @@
 package goquery
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"reflect"
+	"strings"
 )
 
 var pgDialect = DbDialect{
-	TableExistsStmt: `SELECT count(*) FROM information_schema.tables WHERE  table_schema = $1 AND table_name = $2`,
+	TableExistsStmt: `SELECT count(*) FROM information_schema.tables WHERE table_schema = lower(coalesce(nullif($1,''),current_schema())) AND table_name = lower($2)`,
 	Bind: func(field string, i int) string {
 		return fmt.Sprintf("$%d", i+1)
 	},
 	Seq: func(sequence string) string {
 		return fmt.Sprintf("nextval('%s')", sequence)
 	},
+	Upsert: func(table string, fields []string, binds []string, conflict []string, update []string) (string, error) {
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("insert into %s (%s) values (%s) on conflict",
+			table, strings.Join(fields, ","), strings.Join(binds, ",")))
+		if len(conflict) > 0 {
+			builder.WriteString(fmt.Sprintf(" (%s)", strings.Join(conflict, ",")))
+		}
+		if len(update) == 0 {
+			builder.WriteString(" do nothing")
+			return builder.String(), nil
+		}
+		if len(conflict) == 0 {
+			return "", errors.New("upsert update columns require conflict columns")
+		}
+		sets := make([]string, len(update))
+		for i, col := range update {
+			sets[i] = fmt.Sprintf("%s = excluded.%s", col, col)
+		}
+		builder.WriteString(fmt.Sprintf(" do update set %s", strings.Join(sets, ",")))
+		return builder.String(), nil
+	},
+	Returning: func(idfield string) string {
+		return fmt.Sprintf(" returning %s", idfield)
+	},
+	ColumnType: pgColumnType,
+	EmptyList: func(colType string) string {
+		return fmt.Sprintf("select cast(null as %s) where false", colType)
+	},
+	LimitOffset: func(limit int, offset int) string {
+		if limit <= 0 {
+			return fmt.Sprintf("offset %d", offset)
+		}
+		return fmt.Sprintf("limit %d offset %d", limit, offset)
+	},
+	QuoteIdentifier: func(name string) string {
+		return quoteIdentifier(name, strings.ToLower)
+	},
+	RestartIdentity: func(table string, column string, start int64) string {
+		return fmt.Sprintf("alter table %s alter column %s restart with %d", table, column, start)
+	},
 	Url: func(config *RdbmsConfig) string {
 		if config.DbSSLMode == "" {
 			config.DbSSLMode = defaultSSLMode
@@ -22,3 +65,50 @@ var pgDialect = DbDialect{
 			config.Dbuser, config.Dbpass, config.Dbhost, config.Dbport, config.Dbname, config.DbSSLMode)
 	},
 }
+
+func pgColumnType(typ reflect.Type) (string, error) {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ {
+	case dateType, nullTimeType:
+		return "timestamptz", nil
+	case uuidType:
+		return "uuid", nil
+	case bytesType:
+		return "bytea", nil
+	case nullStringType:
+		return "text", nil
+	case nullI32Type:
+		return "integer", nil
+	case nullI64Type:
+		return "bigint", nil
+	case nullF64Type:
+		return "double precision", nil
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return "text", nil
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.Int8, reflect.Int16:
+		return "smallint", nil
+	case reflect.Int32:
+		return "integer", nil
+	case reflect.Int, reflect.Int64:
+		return "bigint", nil
+	case reflect.Float32:
+		return "real", nil
+	case reflect.Float64:
+		return "double precision", nil
+	case reflect.Slice, reflect.Array:
+		elem, err := pgColumnType(typ.Elem())
+		if err != nil {
+			return "", err
+		}
+		return elem + "[]", nil
+	case reflect.Struct, reflect.Map:
+		return "jsonb", nil
+	}
+	return "", fmt.Errorf("unsupported column type: %s", typ)
+}
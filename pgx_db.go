@@ -2,9 +2,12 @@ package goquery
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"math/big"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/georgysavva/scany/pgxscan"
@@ -41,6 +44,9 @@ func (p *PgxRows) Columns() ([]string, error) {
 	return columns, nil
 }
 
+// ColumnTypes returns the go type each column is scanned into for json and csv output.
+// Arrays are scanned into slices of pointers so null elements are preserved, json columns
+// are scanned as raw json and numeric columns keep their full precision.
 func (p *PgxRows) ColumnTypes() ([]reflect.Type, error) {
 	metadata := p.rows.FieldDescriptions()
 	t := make([]reflect.Type, len(metadata))
@@ -59,11 +65,29 @@ func (p *PgxRows) ColumnTypes() ([]reflect.Type, error) {
 		case pgtype.BoolOID:
 			t[i] = reflect.TypeOf(false)
 		case pgtype.NumericOID:
-			t[i] = reflect.TypeOf(float64(0))
+			t[i] = reflect.TypeOf(PgxNumeric{})
 		case pgtype.DateOID, pgtype.TimestampOID, pgtype.TimestamptzOID:
 			t[i] = reflect.TypeOf(time.Time{})
 		case pgtype.ByteaOID:
 			t[i] = reflect.TypeOf([]byte(nil))
+		case pgtype.JSONOID, pgtype.JSONBOID:
+			t[i] = reflect.TypeOf(json.RawMessage(nil))
+		case pgtype.Float8ArrayOID:
+			t[i] = reflect.TypeOf([]*float64(nil))
+		case pgtype.Float4ArrayOID:
+			t[i] = reflect.TypeOf([]*float32(nil))
+		case pgtype.Int8ArrayOID:
+			t[i] = reflect.TypeOf([]*int64(nil))
+		case pgtype.Int4ArrayOID:
+			t[i] = reflect.TypeOf([]*int32(nil))
+		case pgtype.Int2ArrayOID:
+			t[i] = reflect.TypeOf([]*int16(nil))
+		case pgtype.BoolArrayOID:
+			t[i] = reflect.TypeOf([]*bool(nil))
+		case pgtype.TextArrayOID, pgtype.VarcharArrayOID, pgtype.BPCharArrayOID, pgtype.UUIDArrayOID:
+			t[i] = reflect.TypeOf([]*string(nil))
+		case pgtype.DateArrayOID, pgtype.TimestampArrayOID, pgtype.TimestamptzArrayOID:
+			t[i] = reflect.TypeOf([]*time.Time(nil))
 		default:
 			t[i] = reflect.TypeOf("")
 		}
@@ -86,21 +110,77 @@ func (p *PgxRows) ScanStruct(dest interface{}) error {
 	return p.rowScanner.Scan(dest)
 }
 
+func (p *PgxRows) Err() error {
+	return p.rows.Err()
+}
+
 func (p *PgxRows) Close() error {
 	p.rows.Close()
 	return nil
 }
 
-/*
-type PgxBatch struct {
-	BatchSize int
-	batch     *pgx.Batch
+// PgxNumeric is a nullable numeric that is written to json as a number
+// with the precision of the database value.  NaN is written as the string "NaN".
+// pgtype does not decode the numeric infinities added in postgres 14.
+type PgxNumeric struct {
+	pgtype.Numeric
 }
 
-func(pb *PgxBatch) Queue(stmt string, params ...interface{}){
-	pb.batch.Queue(stmt,params)
+func (n PgxNumeric) MarshalJSON() ([]byte, error) {
+	text, ok := numericText(n.Numeric)
+	switch {
+	case !ok:
+		return []byte("null"), nil
+	case n.NaN:
+		return []byte(`"NaN"`), nil
+	}
+	return []byte(text), nil
+}
+
+// numericText formats n as a plain decimal with the scale of the database value,
+// rather than the <int>e<exp> text of pgtype.  ok is false when n is null.
+func numericText(n pgtype.Numeric) (text string, ok bool) {
+	if n.Status != pgtype.Present {
+		return "", false
+	}
+	if n.NaN {
+		return "NaN", true
+	}
+	if n.Int == nil {
+		return "0", true
+	}
+	digits := new(big.Int).Abs(n.Int).String()
+	if n.Exp > 0 {
+		digits += strings.Repeat("0", int(n.Exp))
+	} else if n.Exp < 0 {
+		scale := int(-n.Exp)
+		if len(digits) <= scale {
+			digits = strings.Repeat("0", scale-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-scale] + "." + digits[len(digits)-scale:]
+	}
+	if n.Int.Sign() < 0 {
+		digits = "-" + digits
+	}
+	return digits, true
+}
+
+type PgxBatchResult struct {
+	br pgx.BatchResults
+}
+
+func (pbr PgxBatchResult) Exec() (ExecResult, error) {
+	ct, err := pbr.br.Exec()
+	return PgxExecResult{ct}, err
+}
+
+func (pbr PgxBatchResult) QueryRow() Row {
+	return pbr.br.QueryRow()
+}
+
+func (pbr PgxBatchResult) Close() error {
+	return pbr.br.Close()
 }
-*/
 
 type PgxDb struct {
 	db      *pgxpool.Pool
@@ -136,6 +216,10 @@ func (pdb *PgxDb) Connection() interface{} {
 	return pdb.db
 }
 
+func (pdb *PgxDb) Dialect() DbDialect {
+	return pdb.dialect
+}
+
 func (pdb *PgxDb) querier(tx *Tx) pgxscan.Querier {
 	if tx != nil {
 		return tx.PgxTx()
@@ -150,85 +234,163 @@ func (pdb *PgxDb) execr(tx *Tx) PgxExecr {
 	return pdb.db
 }
 
-func (pdb *PgxDb) Select(dest interface{}, tx *Tx, stmt string, params ...interface{}) error {
-	return pgxscan.Select(context.Background(), pdb.querier(tx), dest, stmt, params...)
+func (pdb *PgxDb) Select(ctx context.Context, dest interface{}, tx *Tx, stmt string, params ...interface{}) error {
+	return pgxscan.Select(ctx, pdb.querier(tx), dest, stmt, params...)
 }
 
-func (pdb *PgxDb) Get(dest interface{}, tx *Tx, stmt string, params ...interface{}) error {
-	return pgxscan.Get(context.Background(), pdb.querier(tx), dest, stmt, params...)
+func (pdb *PgxDb) Get(ctx context.Context, dest interface{}, tx *Tx, stmt string, params ...interface{}) error {
+	return pgxscan.Get(ctx, pdb.querier(tx), dest, stmt, params...)
 }
 
-func (pdb *PgxDb) Query(tx *Tx, stmt string, params ...interface{}) (Rows, error) {
-	rows, err := pdb.querier(tx).Query(context.Background(), stmt, params...)
+func (pdb *PgxDb) Query(ctx context.Context, tx *Tx, stmt string, params ...interface{}) (Rows, error) {
+	rows, err := pdb.querier(tx).Query(ctx, stmt, params...)
 	return &PgxRows{rows, nil}, err
 }
 
 // @DEPRICATED
-func (pdb *PgxDb) Exec(tx *Tx, stmt string, params ...interface{}) error {
-	_, err := pdb.execr(tx).Exec(context.Background(), stmt, params...)
+func (pdb *PgxDb) Exec(ctx context.Context, tx *Tx, stmt string, params ...interface{}) error {
+	_, err := pdb.execr(tx).Exec(ctx, stmt, params...)
 	return err
 }
 
-func (pdb *PgxDb) Execr(tx *Tx, stmt string, params ...interface{}) (ExecResult, error) {
-	ct, err := pdb.execr(tx).Exec(context.Background(), stmt, params...)
+func (pdb *PgxDb) Execr(ctx context.Context, tx *Tx, stmt string, params ...interface{}) (ExecResult, error) {
+	ct, err := pdb.execr(tx).Exec(ctx, stmt, params...)
 	return PgxExecResult{ct}, err
 }
 
-func (pdb *PgxDb) MustExec(tx *Tx, stmt string, params ...interface{}) {
-	_, err := pdb.execr(tx).Exec(context.Background(), stmt, params...)
+func (pdb *PgxDb) MustExec(ctx context.Context, tx *Tx, stmt string, params ...interface{}) {
+	_, err := pdb.execr(tx).Exec(ctx, stmt, params...)
 	if err != nil {
 		panic(err)
 	}
 }
 
-func (pdb *PgxDb) MustExecr(tx *Tx, stmt string, params ...interface{}) ExecResult {
-	ct, err := pdb.execr(tx).Exec(context.Background(), stmt, params...)
+func (pdb *PgxDb) MustExecr(ctx context.Context, tx *Tx, stmt string, params ...interface{}) ExecResult {
+	ct, err := pdb.execr(tx).Exec(ctx, stmt, params...)
 	if err != nil {
 		panic(err)
 	}
 	return PgxExecResult{ct}
 }
 
+// structCopySource feeds a slice of db tagged structs to CopyFrom one record at a time.
+type structCopySource struct {
+	recs reflect.Value
+	i    int
+}
+
+func (s *structCopySource) Next() bool {
+	s.i++
+	return s.i <= s.recs.Len()
+}
+
+func (s *structCopySource) Values() ([]interface{}, error) {
+	rec := s.recs.Index(s.i - 1)
+	if rec.Kind() == reflect.Ptr && rec.IsNil() {
+		return nil, fmt.Errorf("invalid nil record at index %d", s.i-1)
+	}
+	return StructToIArray(rec.Interface()), nil
+}
+
+func (s *structCopySource) Err() error {
+	return nil
+}
+
+// CopyFrom bulk loads a slice of records using the postgres COPY protocol.  Columns are taken
+// from the db tags of the record type.  AUTOINCREMENT and SEQUENCE ids are omitted so the
+// column default is used.
+func (pdb *PgxDb) CopyFrom(ctx context.Context, ds DataSet, recs reflect.Value, tx *Tx) (int64, error) {
+	table := pgx.Identifier(strings.Split(ds.Entity(), "."))
+	columns := StructToColumns(recs.Interface())
+	src := &structCopySource{recs: recs}
+	if tx != nil {
+		return tx.PgxTx().CopyFrom(ctx, table, columns, src)
+	}
+	return pdb.db.CopyFrom(ctx, table, columns, src)
+}
+
 func (pdb *PgxDb) Batch() (Batch, error) {
 	return &pgx.Batch{}, nil
 }
 
-func (pdb *PgxDb) SendBatch(batch Batch) BatchResult {
+// SendBatch sends the batch on the transaction if one is supplied.  The caller
+// is responsible for closing the returned BatchResult.
+func (pdb *PgxDb) SendBatch(ctx context.Context, tx *Tx, batch Batch) BatchResult {
 	pb := batch.(*pgx.Batch)
-	br := pdb.db.SendBatch(context.Background(), pb)
-	br.Close()
-	return br
+	if tx != nil {
+		return PgxBatchResult{tx.PgxTx().SendBatch(ctx, pb)}
+	}
+	return PgxBatchResult{pdb.db.SendBatch(ctx, pb)}
 }
 
 func (pdb *PgxDb) InsertStmt(ds DataSet) (string, error) {
+	if stmt, ok := ds.Commands()[insertkey]; ok {
+		return stmt, nil
+	}
 	return ToInsert(ds, pdb.dialect)
 }
 
-func (pdb *PgxDb) Insert(ds DataSet, rec interface{}, tx *Tx) error {
+func (pdb *PgxDb) UpsertStmt(ds DataSet, conflict []string, update []string) (string, error) {
+	return ToUpsert(ds, pdb.dialect, conflict, update)
+}
+
+func (pdb *PgxDb) Insert(ctx context.Context, stmt string, rec interface{}, tx *Tx) (int64, error) {
+	params := StructToIArray(rec)
+	ct, err := pdb.execr(tx).Exec(ctx, stmt, params...)
+	return ct.RowsAffected(), err
+}
 
-	var err error
-	var stmt string
-	var ok bool
+// InsertReturning runs an insert statement with a returning clause and scans the returned
+// id into the dbid tagged field of rec, which must be a pointer to a struct.
+func (pdb *PgxDb) InsertReturning(ctx context.Context, stmt string, rec interface{}, tx *Tx) (int64, error) {
+	id, err := StructIdPointer(rec)
+	if err != nil {
+		return 0, err
+	}
+	rows, err := pdb.querier(tx).Query(ctx, stmt, StructToIArray(rec)...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var count int64
+	if rows.Next() {
+		err = rows.Scan(id)
+		if err != nil {
+			return 0, err
+		}
+		count++
+	}
+	return count, rows.Err()
+}
 
-	if stmt, ok = ds.Commands()["insert"]; !ok {
-		stmt, err = ToInsert(ds, pdb.dialect)
+func (pdb *PgxDb) UpdateStmt(ds DataSet) (string, error) {
+	if stmt, ok := ds.Commands()[updatekey]; ok {
+		return stmt, nil
 	}
+	return ToUpdateStmt(ds, pdb.dialect)
+}
+
+func (pdb *PgxDb) Update(ctx context.Context, ds DataSet, rec interface{}, tx *Tx) (int64, error) {
+	stmt, err := pdb.UpdateStmt(ds)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	params := StructToIArray(rec)
-	if tx == nil {
-		_, err = pdb.db.Exec(context.Background(), stmt, params...)
-		return err
-	} else {
-		pgxtx := tx.PgxTx()
-		_, err = pgxtx.Exec(context.Background(), stmt, params...)
-		return err
+	params, err := StructToUpdateIArray(rec)
+	if err != nil {
+		return 0, err
 	}
+	ct, err := pdb.execr(tx).Exec(ctx, stmt, params...)
+	return ct.RowsAffected(), err
+}
 
+func (pdb *PgxDb) DeleteStmt(ds DataSet) (string, error) {
+	if stmt, ok := ds.Commands()[deletekey]; ok {
+		return stmt, nil
+	}
+	return ToDelete(ds, pdb.dialect)
 }
 
-func (pdb *PgxDb) Transaction() (Tx, error) {
-	tx, err := pdb.db.Begin(context.Background())
+func (pdb *PgxDb) Transaction(ctx context.Context) (Tx, error) {
+	tx, err := pdb.db.Begin(ctx)
 	return Tx{tx}, err
 }
@@ -2,6 +2,7 @@ package goquery
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -121,6 +122,36 @@ func TestPgxJson(t *testing.T) {
 	}
 }
 
+func TestPgxJsonTx(t *testing.T) {
+	correctResult := `[{"id":5,"location":"Tx Lake"}]`
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	tx, err := store.NewTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	store.MustExec(&tx, "insert into fishing_spots (location) values ('Tx Lake')")
+
+	json, err := store.Select("select * from fishing_spots where id>4").
+		Tx(&tx).
+		IsJsonArray(true).
+		FetchJSON()
+	if err != nil {
+		t.Errorf("Failed JSON Tx Test: %s\n", err)
+	}
+	if string(json) != correctResult {
+		t.Errorf("Failed JSON Tx Test: Got %s want %s", json, correctResult)
+	}
+
+	var builder strings.Builder
+	err = store.GetJSON(&builder, QueryInput{Statement: "select * from fishing_spots where id>4"}, OutputOptions{IsArray: true})
+	if err != nil || builder.String() != "[]" {
+		t.Errorf("Failed JSON Tx Test: Got %s %v want [] outside the transaction", builder.String(), err)
+	}
+}
+
 func TestPgxSlice(t *testing.T) {
 	ap := "Alpine Frove"
 	rt := "Rivertown"
@@ -286,10 +317,13 @@ func TestPgxInsertBatch(t *testing.T) {
 
 	store := pgxsetup(t)
 	defer pgxteardown(store, t)
-	err := store.Insert(&fsTbl).Records(&fs).Batch(true).BatchSize(len(fs)).Execute()
+	rows, err := store.Insert(&fsTbl).Records(&fs).Batch(true).BatchSize(1000).Execr()
 	if err != nil {
 		t.Error(err)
 	}
+	if rows != int64(count) {
+		t.Errorf("Failed Batch Test: Got %d rows want %d", rows, count)
+	}
 
 }
 
@@ -361,3 +395,829 @@ func TestJson(t *testing.T) {
 	}
 	t.Log(dest)
 }
+
+func TestPgxCancelledContext(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dest := []FishingSpot{}
+	err := store.Select("select * from fishing_spots").
+		Context(ctx).
+		Dest(&dest).
+		Fetch()
+	if err == nil {
+		t.Error("Failed Context Test: expected an error from a cancelled context")
+	}
+}
+
+type FishingSpotRec struct {
+	ID       int32   `db:"id" dbid:"SEQUENCE" idsequence:"fishing_spots_id_seq"`
+	Location *string `db:"location"`
+}
+
+var fsRecTbl TableDataSet = TableDataSet{
+	Name:        "fishing_spots",
+	TableFields: FishingSpotRec{},
+}
+
+func TestPgxUpdate(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	l1 := "Alpine Grove"
+	rows, err := store.Update(&fsRecTbl).Records(FishingSpotRec{1, &l1}).Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if rows != 1 {
+		t.Errorf("Failed Update Test: Got %d rows want 1", rows)
+	}
+
+	l2 := "River Town"
+	l3 := "Pine Isle"
+	recs := []FishingSpotRec{{2, &l2}, {3, &l3}}
+	for _, batch := range []bool{false, true} {
+		rows, err = store.Update(&fsRecTbl).Records(&recs).Batch(batch).BatchSize(1).Execute()
+		if err != nil {
+			t.Error(err)
+		}
+		if rows != 2 {
+			t.Errorf("Failed Update Test: Got %d rows want 2 (batch=%t)", rows, batch)
+		}
+	}
+
+	dest := FishingSpot{}
+	err = store.Select("select * from fishing_spots where id=$1").Params(2).Dest(&dest).Fetch()
+	if err != nil {
+		t.Error(err)
+	}
+	if dest.Location == nil || *dest.Location != l2 {
+		t.Errorf("Failed Update Test: Got %v want %s", dest.Location, l2)
+	}
+
+	var nilRec *FishingSpotRec
+	for _, invalid := range []interface{}{nil, nilRec, 1} {
+		if _, err = store.Update(&fsRecTbl).Records(invalid).Execute(); err == nil {
+			t.Errorf("Failed Update Test: expected an error for records %v", invalid)
+		}
+	}
+}
+
+func TestPgxUpdateBatchRollback(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	before := FishingSpot{}
+	err := store.Select("select * from fishing_spots where id=$1").Params(1).Dest(&before).Fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//postgres rejects the null byte in the second batch
+	l1 := "Changed"
+	l2 := "Invalid\x00"
+	recs := []FishingSpotRec{{1, &l1}, {2, &l2}}
+	if _, err = store.Update(&fsRecTbl).Records(recs).Batch(true).BatchSize(1).Execute(); err == nil {
+		t.Fatal("Failed Update Batch Rollback Test: expected an error")
+	}
+
+	after := FishingSpot{}
+	err = store.Select("select * from fishing_spots where id=$1").Params(1).Dest(&after).Fetch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(before, after) {
+		t.Errorf("Failed Update Batch Rollback Test: Got %v want %v", *after.Location, *before.Location)
+	}
+}
+
+func TestPgxDelete(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	rows, err := store.Delete(&fsRecTbl).Records(FishingSpotRec{ID: 1}).Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if rows != 1 {
+		t.Errorf("Failed Delete Test: Got %d rows want 1", rows)
+	}
+
+	rows, err = store.Delete(&fsRecTbl).Records([]FishingSpotRec{{ID: 2}, {ID: 3}}).Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if rows != 2 {
+		t.Errorf("Failed Delete Test: Got %d rows want 2", rows)
+	}
+
+	rows, err = store.Delete(&fsRecTbl).Suffix("where id in ($1)").Params([]interface{}{}).Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if rows != 0 {
+		t.Errorf("Failed Delete Test: Got %d rows want 0 for an empty in list", rows)
+	}
+
+	rows, err = store.Delete(&fsRecTbl).Suffix("where id>=$1").Params(4).Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if rows != 1 {
+		t.Errorf("Failed Delete Test: Got %d rows want 1", rows)
+	}
+
+	_, err = store.Delete(&fsRecTbl).Execute()
+	if err == nil {
+		t.Error("Failed Delete Test: expected an error for a delete without criteria")
+	}
+
+	_, err = store.Delete(&fsRecTbl).Records(FishingSpotRec{ID: 1}).Suffix("where id=$1").Params(2).Execute()
+	if err == nil {
+		t.Error("Failed Delete Test: expected an error for a delete with records and a suffix")
+	}
+}
+
+func TestPgxUpsert(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	fsTbl := TableDataSet{
+		Name:        "fishing_spots",
+		TableFields: FishingSpot{},
+	}
+
+	l1 := "Alpine Grove"
+	l10 := "New Spot 10"
+	fs := []FishingSpot{{1, &l1}, {10, &l10}}
+	for _, batch := range []bool{false, true} {
+		err := store.Insert(&fsTbl).
+			Records(fs).
+			Upsert([]string{"id"}, []string{"location"}).
+			Batch(batch).
+			Execute()
+		if err != nil {
+			t.Error(err)
+		}
+	}
+
+	dest := []FishingSpot{}
+	err := store.Select("select * from fishing_spots where id in (1,10) order by id").Dest(&dest).Fetch()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(dest) != 2 || *dest[0].Location != l1 || *dest[1].Location != l10 {
+		t.Errorf("Failed Upsert Test: Got %v", dest)
+	}
+}
+
+func TestPgxInsertReturnId(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	l5 := "Spot 5"
+	rec := FishingSpotRec{Location: &l5}
+	err := store.Insert(&fsRecTbl).Records(&rec).ReturnId(true).Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if rec.ID != 5 {
+		t.Errorf("Failed Return Id Test: Got %d want 5", rec.ID)
+	}
+
+	for _, batch := range []bool{false, true} {
+		l := "Another Spot"
+		recs := []FishingSpotRec{{Location: &l}, {Location: &l}}
+		err = store.Insert(&fsRecTbl).Records(recs).Batch(batch).ReturnId(true).Execute()
+		if err != nil {
+			t.Error(err)
+		}
+		if recs[0].ID == 0 || recs[1].ID != recs[0].ID+1 {
+			t.Errorf("Failed Return Id Test: Got ids %d,%d (batch=%t)", recs[0].ID, recs[1].ID, batch)
+		}
+	}
+}
+
+func TestPgxUpsertReturnId(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+	store.MustExec(NoTx, "create unique index fishing_spots_location on fishing_spots (location)")
+
+	for _, batch := range []bool{false, true} {
+		existing := "Rivertown"
+		added := fmt.Sprintf("Upsert Spot %t", batch)
+		recs := []FishingSpotRec{{Location: &existing}, {Location: &added}}
+		rows, err := store.Insert(&fsRecTbl).
+			Records(recs).
+			Upsert([]string{"location"}, nil).
+			Batch(batch).
+			ReturnId(true).
+			Execr()
+		if err != nil {
+			t.Error(err)
+		}
+		if rows != 1 || recs[0].ID != 0 || recs[1].ID == 0 {
+			t.Errorf("Failed Upsert Return Id Test: Got %d rows and ids %d,%d (batch=%t)", rows, recs[0].ID, recs[1].ID, batch)
+		}
+	}
+}
+
+func TestPgxInsertBatchErrors(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	fsTbl := TableDataSet{
+		Name:        "fishing_spots",
+		TableFields: FishingSpot{},
+	}
+	l := "Duplicate"
+	fs := []FishingSpot{{10, &l}, {11, &l}, {12, &l}, {1, &l}, {13, &l}}
+
+	_, err := store.Insert(&fsTbl).Records(fs).Batch(true).BatchSize(2).Execr()
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Index != 3 {
+		t.Errorf("Failed Batch Error Test: Got %v want a BatchError for record 3", err)
+	}
+
+	var count int
+	store.Select("select count(*) from fishing_spots").Dest(&count).Fetch()
+	if count != 4 {
+		t.Errorf("Failed Batch Error Test: Got %d rows want 4", count)
+	}
+
+	tx, err := store.NewTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := store.Insert(&fsTbl).Records(fs[:3]).Batch(true).Tx(&tx).Execr()
+	if err != nil || rows != 3 {
+		t.Errorf("Failed Batch Tx Test: Got %d rows and error %v", rows, err)
+	}
+	tx.Rollback()
+	store.Select("select count(*) from fishing_spots").Dest(&count).Fetch()
+	if count != 4 {
+		t.Errorf("Failed Batch Tx Test: Got %d rows after rollback want 4", count)
+	}
+}
+
+func TestPgxCopy(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	count := 10000
+	recs := make([]FishingSpotRec, count)
+	for i := 0; i < count; i++ {
+		val := strconv.Itoa(i)
+		recs[i] = FishingSpotRec{Location: &val}
+	}
+	rows, err := store.Insert(&fsRecTbl).Records(recs).Copy(true).Execr()
+	if err != nil {
+		t.Error(err)
+	}
+	if rows != int64(count) {
+		t.Errorf("Failed Copy Test: Got %d rows want %d", rows, count)
+	}
+}
+
+func TestPgxCopyPointerRecords(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	count := 100
+	recs := make([]*FishingSpotRec, count)
+	for i := 0; i < count; i++ {
+		val := strconv.Itoa(i)
+		recs[i] = &FishingSpotRec{Location: &val}
+	}
+	rows, err := store.Insert(&fsRecTbl).Records(recs).Copy(true).Execr()
+	if err != nil {
+		t.Error(err)
+	}
+	if rows != int64(count) {
+		t.Errorf("Failed Copy Test: Got %d rows want %d", rows, count)
+	}
+
+	_, err = store.Insert(&fsRecTbl).Records(recs).Copy(true).StatementKey("insert").Execr()
+	if err == nil {
+		t.Error("Expected an error for a copy insert with a statement key")
+	}
+}
+
+func TestPgxETL(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	source := TableDataSet{
+		Name:        "fishing_spots",
+		TableFields: FishingSpot{},
+	}
+	dest := TableDataSet{
+		Name:        "fishing_spots_copy",
+		TableFields: FishingSpot{},
+	}
+	etl := NewETL(store, store, TransferOptions{CreateTable: true, CommitSize: 3})
+	count, err := etl.Transfer(context.Background(), &source, &dest)
+	defer store.MustExec(NoTx, "drop table if exists fishing_spots_copy")
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 4 {
+		t.Errorf("Failed ETL Test: Got %d records want 4", count)
+	}
+
+	copied := []FishingSpot{}
+	err = store.Select("select * from fishing_spots_copy order by id").Dest(&copied).Fetch()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(copied) != 4 || copied[3].Location != nil {
+		t.Errorf("Failed ETL Test: Got %v", copied)
+	}
+}
+
+type FishingSpotAuto struct {
+	ID       int32   `db:"id" dbid:"AUTOINCREMENT"`
+	Location *string `db:"location"`
+}
+
+func TestPgxETLIds(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	store.MustExec(NoTx, "delete from fishing_spots where id=2")
+	source := TableDataSet{
+		Name:        "fishing_spots",
+		TableFields: FishingSpotRec{},
+	}
+	dest := TableDataSet{
+		Name:        "fishing_spots_ids",
+		TableFields: FishingSpotRec{},
+	}
+	etl := NewETL(store, store, TransferOptions{CreateTable: true, CommitSize: 2})
+	_, err := etl.Transfer(context.Background(), &source, &dest)
+	defer store.MustExec(NoTx, "drop table if exists fishing_spots_ids")
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied := []FishingSpotRec{}
+	err = store.Select("select * from fishing_spots_ids order by id").Dest(&copied).Fetch()
+	if err != nil {
+		t.Error(err)
+	}
+	ids := []int32{}
+	for _, c := range copied {
+		ids = append(ids, c.ID)
+	}
+	if !reflect.DeepEqual(ids, []int32{1, 3, 4}) {
+		t.Errorf("Failed ETL Ids Test: Got ids %v want [1 3 4]", ids)
+	}
+
+	autoSource := TableDataSet{
+		Name:        "fishing_spots_ids",
+		TableFields: FishingSpotAuto{},
+	}
+	autoDest := TableDataSet{
+		Name:        "fishing_spots_auto",
+		TableFields: FishingSpotAuto{},
+	}
+	etl = NewETL(store, store, TransferOptions{CreateTable: true})
+	_, err = etl.Transfer(context.Background(), &autoSource, &autoDest)
+	defer store.MustExec(NoTx, "drop table if exists fishing_spots_auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := "New Spot"
+	rec := FishingSpotAuto{Location: &l}
+	_, err = store.Insert(&autoDest).Records(&rec).ReturnId(true).Execr()
+	if err != nil {
+		t.Error(err)
+	}
+	if rec.ID != 5 {
+		t.Errorf("Failed ETL Ids Test: Got new id %d want 5", rec.ID)
+	}
+}
+
+func TestPgxCsv(t *testing.T) {
+	correctResult := "id,location\n1,Alpine Frove\n2,Rivertown\n3,Pine Island\n4,\n"
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	builder := strings.Builder{}
+	err := store.
+		Select("select * from fishing_spots order by id").
+		OutputCsv(&builder).
+		Fetch()
+	if err != nil {
+		t.Errorf("Failed CSV Test: %s\n", err)
+	}
+	if builder.String() != correctResult {
+		t.Errorf("Failed CSV Test: Got %s want %s", builder.String(), correctResult)
+	}
+}
+
+func TestPgxImportCsv(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	data := "spot,location\n" +
+		"1,Lake Placid\n" +
+		"2,\n" +
+		"3,\"Bass Pond, North\"\n" +
+		"4\n" +
+		"5,Trout Run\n"
+	result, err := store.Import(&fsRecTbl).
+		HeaderMap(map[string]string{"spot": "-"}).
+		SkipBadRows(true).
+		BatchSize(2).
+		FromCsv(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Records != 4 {
+		t.Errorf("Failed Import Test: Got %d records want 4", result.Records)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0].Line != 5 {
+		t.Errorf("Failed Import Test: Got skipped %v want line 5", result.Skipped)
+	}
+
+	var count int
+	err = store.Select("select count(*) from fishing_spots where location is null").Dest(&count).Fetch()
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 2 {
+		t.Errorf("Failed Import Test: Got %d null locations want 2", count)
+	}
+
+	_, err = store.Import(&fsRecTbl).FromCsv(strings.NewReader("location\nok\n\"bad\"quote\n"))
+	var ie *ImportError
+	if !errors.As(err, &ie) || ie.Line != 3 {
+		t.Errorf("Failed Import Test: Got %v want an error on line 3", err)
+	}
+
+	store.MustExec(NoTx, "alter table fishing_spots add constraint location_length check (length(location) < 20)")
+	data = "location\nShort Creek\nA location name that is too long\nLong Lake\n"
+	result, err = store.Import(&fsRecTbl).SkipBadRows(true).FromCsv(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Records != 2 || len(result.Skipped) != 1 || result.Skipped[0].Line != 3 {
+		t.Errorf("Failed Import Test: Got %d records and skipped %v want 2 records and line 3", result.Records, result.Skipped)
+	}
+}
+
+func TestPgxNdjson(t *testing.T) {
+	correctResult := "{\"id\":1,\"location\":\"Alpine Frove\"}\n{\"id\":2,\"location\":\"Rivertown\"}\n" +
+		"{\"id\":3,\"location\":\"Pine Island\"}\n{\"id\":4,\"location\":null}\n"
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	builder := strings.Builder{}
+	err := store.
+		Select("select * from fishing_spots order by id").
+		OutputNdjson(&builder).
+		Fetch()
+	if err != nil {
+		t.Errorf("Failed NDJSON Test: %s\n", err)
+	}
+	if builder.String() != correctResult {
+		t.Errorf("Failed NDJSON Test: Got %s want %s", builder.String(), correctResult)
+	}
+}
+
+func TestPgxJsonTypes(t *testing.T) {
+	correctResult := `[{"id":1,"json_attr":{"age":8,"name":"jack"},"intlist":[3,null],"boollist":[true],"amount":12345678901234567890.0100,"flag":null},` +
+		`{"id":2,"json_attr":null,"intlist":null,"boollist":null,"amount":null,"flag":false}]`
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+	store.MustExec(NoTx, `insert into json_test values (1,'{"name":"jack","age":8}'),(2,null)`)
+	store.MustExec(NoTx, `insert into arrays_test values (1,'{3,null}','{true}'),(2,null,null)`)
+
+	builder := strings.Builder{}
+	err := store.
+		Select(`select j.id, j.json_attr, a.intlist, a.boollist,
+			case when j.id=1 then 12345678901234567890.0100 end as amount,
+			case when j.id=2 then false end as flag
+			from json_test j join arrays_test a on a.id=j.id order by j.id`).
+		IsJsonArray(true).
+		CamelCase(false).
+		OutputJson(&builder).
+		Fetch()
+	if err != nil {
+		t.Errorf("Failed JSON Types Test: %s\n", err)
+	}
+	if builder.String() != correctResult {
+		t.Errorf("Failed JSON Types Test: Got %s want %s", builder.String(), correctResult)
+	}
+}
+
+func TestPgxImportJson(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	array := "[{\"location\":\"Lake Placid\"},\n{\"location\":null},\n{\"location\":7}]"
+	result, err := store.Import(&fsRecTbl).SkipBadRows(true).FromJson(strings.NewReader(array))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Records != 2 || len(result.Skipped) != 1 || result.Skipped[0].Line != 3 {
+		t.Errorf("Failed JSON Import Test: Got %d records and skipped %v", result.Records, result.Skipped)
+	}
+
+	ndjson := "{\"location\":\"Trout Run\"}\n{\"location\":\"Bass Pond\"}\n"
+	result, err = store.Import(&fsRecTbl).FromJson(strings.NewReader(ndjson))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Records != 2 {
+		t.Errorf("Failed JSON Import Test: Got %d records want 2", result.Records)
+	}
+	var count int
+	err = store.Select("select count(*) from fishing_spots").Dest(&count).Fetch()
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 8 {
+		t.Errorf("Failed JSON Import Test: Got %d rows want 8", count)
+	}
+}
+
+func TestPgxGenerics(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	spots, err := Query[FishingSpot](store, "select * from fishing_spots where id>$1 order by id", 2)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(spots) != 2 || spots[0].ID != 3 {
+		t.Errorf("Failed Generics Test: Got %v want ids 3 and 4", spots)
+	}
+
+	spot, err := Get[FishingSpot](store, "select * from fishing_spots where id=$1", 1)
+	if err != nil {
+		t.Error(err)
+	}
+	if spot.ID != 1 || *spot.Location != "Alpine Frove" {
+		t.Errorf("Failed Generics Test: Got %v want id 1", spot)
+	}
+
+	count, err := FetchOne[int](store.Select("select count(*) from fishing_spots"))
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 4 {
+		t.Errorf("Failed Generics Test: Got %d rows want 4", count)
+	}
+
+	ids, err := Fetch[int32](store.Select("select id from fishing_spots order by id"))
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(ids, []int32{1, 2, 3, 4}) {
+		t.Errorf("Failed Generics Test: Got %v want [1 2 3 4]", ids)
+	}
+}
+
+func TestPgxNamedParams(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	fsTbl := TableDataSet{
+		Name: "fishing_spots",
+		Statements: Statements{
+			"by-location": `select * from fishing_spots where location=:location or id=:id order by id`,
+		},
+	}
+	dest := []FishingSpot{}
+	err := store.Select().
+		DataSet(&fsTbl).
+		StatementKey("by-location").
+		NamedParams(map[string]interface{}{"location": "Rivertown", "id": 4}).
+		Dest(&dest).
+		Fetch()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(dest) != 2 || dest[0].ID != 2 || dest[1].ID != 4 {
+		t.Errorf("Failed Named Params Test: Got %v want ids 2 and 4", dest)
+	}
+
+	err = store.Exec(NoTx, "update fishing_spots set location=:location where id=:id",
+		Named(map[string]interface{}{"location": "River Town", "id": 2}))
+	if err != nil {
+		t.Error(err)
+	}
+
+	recTbl := TableDataSet{
+		Name: "fishing_spots",
+		Statements: Statements{
+			"insert": `insert into fishing_spots (location, id) values (:location, :id)`,
+			"update": `update fishing_spots set location=:location where id=:id`,
+		},
+		TableFields: FishingSpotRec{},
+	}
+	l5 := "Lake Five"
+	_, err = store.Insert(&recTbl).Records([]FishingSpotRec{{5, &l5}}).Batch(true).Execr()
+	if err != nil {
+		t.Error(err)
+	}
+	l1 := "Alpine Grove"
+	rows, err := store.Update(&recTbl).Records(FishingSpotRec{1, &l1}).Execute()
+	if err != nil || rows != 1 {
+		t.Errorf("Failed Named Params Test: Got %d rows and %v updating with a named command", rows, err)
+	}
+	rows, err = store.Delete(&recTbl).Suffix("where id=:id").NamedParams(map[string]interface{}{"id": 4}).Execute()
+	if err != nil || rows != 1 {
+		t.Errorf("Failed Named Params Test: Got %d rows and %v deleting with named params", rows, err)
+	}
+
+	dest = []FishingSpot{}
+	err = store.Select("select * from fishing_spots order by id").Dest(&dest).Fetch()
+	if err != nil {
+		t.Error(err)
+	}
+	locations := []string{}
+	for _, d := range dest {
+		locations = append(locations, *d.Location)
+	}
+	if !reflect.DeepEqual(locations, []string{"Alpine Grove", "River Town", "Pine Island", "Lake Five"}) {
+		t.Errorf("Failed Named Params Test: Got %v", locations)
+	}
+}
+
+func TestPgxSliceParams(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	ids, err := Fetch[int32](store.Select("select id from fishing_spots where id in ($1) order by id").Params([]int{1, 3}))
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(ids, []int32{1, 3}) {
+		t.Errorf("Failed Slice Params Test: Got %v want [1 3]", ids)
+	}
+
+	ids, err = Fetch[int32](store.Select("select id from fishing_spots where location in (:locations) order by id").
+		NamedParams(map[string]interface{}{"locations": []string{"Rivertown", "Pine Island"}}))
+	if err != nil {
+		t.Error(err)
+	}
+	if !reflect.DeepEqual(ids, []int32{2, 3}) {
+		t.Errorf("Failed Slice Params Test: Got %v want [2 3]", ids)
+	}
+
+	count, err := Get[int](store, "select count(*) from fishing_spots where id not in ($1)", []int{})
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 4 {
+		t.Errorf("Failed Slice Params Test: Got %d rows want 4", count)
+	}
+
+	store.MustExec(NoTx, "delete from fishing_spots where id in ($1)", []int32{1, 2})
+	count, err = Get[int](store, "select count(*) from fishing_spots")
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 2 {
+		t.Errorf("Failed Slice Params Test: Got %d rows want 2", count)
+	}
+}
+
+func TestPgxCriteria(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	spots, err := Fetch[FishingSpot](store.Select("select * from fishing_spots where id > $1").
+		Params(1).
+		Where(Or(Like("location", "%town"), IsNull("location"))).
+		OrderBy(Desc("id")))
+	if err != nil {
+		t.Error(err)
+	}
+	if len(spots) != 2 || spots[0].ID != 4 || spots[1].ID != 2 {
+		t.Errorf("Failed Criteria Test: Got %v want ids 4 and 2", spots)
+	}
+
+	dest := []FishingSpot{}
+	err = store.Select("select * from fishing_spots").
+		Where(In("id", []int{1, 2, 3}), Ne("location", "Rivertown")).
+		OrderBy(Asc("id")).
+		Limit(1).
+		Offset(1).
+		Dest(&dest).
+		Fetch()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(dest) != 1 || dest[0].ID != 3 {
+		t.Errorf("Failed Criteria Test: Got %v want id 3", dest)
+	}
+
+	err = store.Select("select * from fishing_spots").Where(Eq("id=1 or 1", 1)).Dest(&dest).Fetch()
+	if err == nil {
+		t.Error("Failed Criteria Test: expected an invalid column error")
+	}
+}
+
+func TestPgxIdentifiers(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	ds := TableDataSet{Name: "fishing_spots", TableFields: FishingSpot{}}
+	dest := []FishingSpot{}
+	err := store.Select("select * from %s order by %s desc").
+		DataSet(&ds).
+		Apply(DataSetIdent("fishing_spots"), DataSetIdent("ID")).
+		Dest(&dest).
+		Fetch()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(dest) != 4 || dest[0].ID != 4 {
+		t.Errorf("Failed Identifier Test: Got %v want 4 rows ordered by id desc", dest)
+	}
+
+	err = store.Select("select * from fishing_spots order by %s").
+		DataSet(&ds).
+		Apply(DataSetIdent("id; drop table fishing_spots")).
+		Dest(&dest).
+		Fetch()
+	if err == nil {
+		t.Error("Failed Identifier Test: expected an invalid identifier error")
+	}
+}
+
+func TestPgxPage(t *testing.T) {
+	store := pgxsetup(t)
+	defer pgxteardown(store, t)
+
+	var ids []int32
+	token := ""
+	for pages := 0; pages < 5; pages++ {
+		spots, next, err := FetchPage[FishingSpot](store.Select("select * from fishing_spots").
+			OrderBy(Desc("id")).
+			Page(3, token))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, spot := range spots {
+			ids = append(ids, spot.ID)
+		}
+		if next == "" {
+			break
+		}
+		token = next
+	}
+	if !reflect.DeepEqual(ids, []int32{4, 3, 2, 1}) {
+		t.Errorf("Failed Page Test: Got %v want [4 3 2 1]", ids)
+	}
+
+	//rows with null keys sort last in ascending order
+	var buf strings.Builder
+	token, err := store.Select("select * from fishing_spots").
+		OrderBy(Asc("location"), Asc("id")).
+		Page(3, "").
+		OutputCsv(&buf).
+		FetchPage()
+	if err != nil || token == "" {
+		t.Fatalf("Failed Page Test: %s %q", err, token)
+	}
+	buf.Reset()
+	token, err = store.Select("select * from fishing_spots").
+		OrderBy(Asc("location"), Asc("id")).
+		Page(3, token).
+		OutputJson(&buf).
+		IsJsonArray(true).
+		FetchPage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" || buf.String() != `[{"id":4,"location":null}]` {
+		t.Errorf("Failed Page Test: Got %s %q want the last row and no next page", buf.String(), token)
+	}
+
+	//a page ending with a null key falls back to an offset
+	buf.Reset()
+	token, err = store.Select("select * from fishing_spots").
+		OrderBy(Desc("location")).
+		Page(1, "").
+		OutputCsv(&buf).
+		FetchPage()
+	if err != nil || buf.String() != "id,location\n4,\n" {
+		t.Fatalf("Failed Page Test: Got %s %q", err, buf.String())
+	}
+	buf.Reset()
+	token, err = store.Select("select * from fishing_spots").
+		OrderBy(Desc("location")).
+		Page(3, token).
+		OutputJson(&buf).
+		IsJsonArray(true).
+		FetchPage()
+	correctResult := `[{"id":2,"location":"Rivertown"},{"id":3,"location":"Pine Island"},{"id":1,"location":"Alpine Frove"}]`
+	if err != nil || token != "" || buf.String() != correctResult {
+		t.Errorf("Failed Page Test: Got %s %q want %s", buf.String(), token, correctResult)
+	}
+}
@@ -1,11 +1,15 @@
 package goquery
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"reflect"
+
+	"github.com/jackc/pgx/v4"
 )
 
 //@TODO panic on error is not complete
@@ -42,13 +46,28 @@ func (sds *RdbmsDataStore) Connection() interface{} {
 	return sds.db.Connection()
 }
 
+func (sds *RdbmsDataStore) Dialect() DbDialect {
+	return sds.db.Dialect()
+}
+
 func (sds *RdbmsDataStore) NewTransaction() (Tx, error) {
-	return sds.db.Transaction()
+	return sds.NewTransactionContext(context.Background())
+}
+
+func (sds *RdbmsDataStore) NewTransactionContext(ctx context.Context) (Tx, error) {
+	return sds.db.Transaction(ctx)
 }
 
-func (sds *RdbmsDataStore) Transaction(fn TransactionFunction) (err error) {
+func (sds *RdbmsDataStore) Transaction(fn TransactionFunction) error {
+	return sds.TransactionContext(context.Background(), fn)
+}
+
+// TransactionContext runs fn in a transaction started with ctx.  If ctx is
+// cancelled before the transaction finishes the driver aborts the pending work
+// and the commit fails.
+func (sds *RdbmsDataStore) TransactionContext(ctx context.Context, fn TransactionFunction) (err error) {
 	var tx Tx
-	tx, err = sds.NewTransaction()
+	tx, err = sds.NewTransactionContext(ctx)
 	if err != nil {
 		log.Printf("Unable to start transaction: %s\n", err)
 		return err
@@ -79,7 +98,15 @@ func (sds *RdbmsDataStore) Transaction(fn TransactionFunction) (err error) {
 }
 
 func (sds *RdbmsDataStore) Fetch(tx *Tx, qi QueryInput, qo QueryOutput, dest interface{}) error {
-	sstmt, err := getSelectStatement(qi.DataSet, qi.StatementKey, qi.Statement, qi.Suffix, qi.StmtAppends, dest)
+	return sds.FetchContext(context.Background(), tx, qi, qo, dest)
+}
+
+func (sds *RdbmsDataStore) FetchContext(ctx context.Context, tx *Tx, qi QueryInput, qo QueryOutput, dest interface{}) error {
+	pqi, token, err := pageInput(qi)
+	if err != nil {
+		return err
+	}
+	sstmt, params, err := sds.selectStatement(pqi, dest)
 	if err != nil {
 		return err
 	}
@@ -89,7 +116,7 @@ func (sds *RdbmsDataStore) Fetch(tx *Tx, qi QueryInput, qo QueryOutput, dest int
 	}
 
 	if qo.rowFunction != nil {
-		rows, err := sds.FetchRows(tx, qi)
+		rows, err := sds.FetchRowsContext(ctx, tx, qi)
 		if err != nil {
 			return err
 		}
@@ -112,16 +139,21 @@ func (sds *RdbmsDataStore) Fetch(tx *Tx, qi QueryInput, qo QueryOutput, dest int
 		return nil
 	} else {
 		switch qo.OutputFormat {
-		case JSON:
-			return sds.GetJSON(qo.Writer, qi, qo.Options)
-		case CSV:
-			return errors.New("CSV is not implemented.")
-			//return sds.GetCSV()
+		case JSON, NDJSON, CSV:
+			return sds.writeRows(ctx, tx, qo, qi)
 		default:
-			if isSlice(dest) {
-				err = sds.db.Select(dest, tx, sstmt, qi.BindParams...)
+			if qi.Page != nil {
+				if !isSlice(dest) {
+					return errors.New("a paged query requires a slice destination")
+				}
+				err = sds.db.Select(ctx, dest, tx, sstmt, params...)
+				if err == nil {
+					pageSlice(reflect.Indirect(reflect.ValueOf(dest)), qi.Page, token, qi.Order)
+				}
+			} else if isSlice(dest) {
+				err = sds.db.Select(ctx, dest, tx, sstmt, params...)
 			} else {
-				err = sds.db.Get(dest, tx, sstmt, qi.BindParams...)
+				err = sds.db.Get(ctx, dest, tx, sstmt, params...)
 			}
 		}
 
@@ -133,15 +165,62 @@ func (sds *RdbmsDataStore) Fetch(tx *Tx, qi QueryInput, qo QueryOutput, dest int
 }
 
 func (sds *RdbmsDataStore) FetchRows(tx *Tx, qi QueryInput) (Rows, error) {
-	sstmt, err := getSelectStatement(qi.DataSet, qi.StatementKey, qi.Statement, qi.Suffix, qi.StmtAppends, nil)
+	return sds.FetchRowsContext(context.Background(), tx, qi)
+}
+
+// FetchRowsContext runs the query and returns its rows.  Paged queries return the rows of
+// the requested page and set the next page token once the page has been read.
+func (sds *RdbmsDataStore) FetchRowsContext(ctx context.Context, tx *Tx, qi QueryInput) (Rows, error) {
+	pqi, token, err := pageInput(qi)
 	if err != nil {
 		return nil, err
 	}
-	return sds.db.Query(tx, sstmt, qi.BindParams...)
+	sstmt, params, err := sds.selectStatement(pqi, nil)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := sds.db.Query(ctx, tx, sstmt, params...)
+	if err != nil || qi.Page == nil {
+		return rows, err
+	}
+	return &pageRows{Rows: rows, page: qi.Page, token: token, order: qi.Order}, nil
+}
+
+// selectStatement resolves the query statement and its bind parameters, quoting applied
+// identifiers, binding named parameters, applying criteria and expanding slice parameters
+// in the store dialect.
+func (sds *RdbmsDataStore) selectStatement(qi QueryInput, dest interface{}) (string, []interface{}, error) {
+	appends, err := applyIdentifiers(qi.StmtAppends, qi.DataSet, dest, sds.db.Dialect())
+	if err != nil {
+		return "", nil, err
+	}
+	sstmt, err := getSelectStatement(qi.DataSet, qi.StatementKey, qi.Statement, qi.Suffix, appends, dest)
+	if err != nil {
+		return "", nil, err
+	}
+	params := qi.BindParams
+	if qi.NamedParams != nil {
+		if len(qi.BindParams) > 0 {
+			return "", nil, errors.New("a query cannot use both positional and named parameters")
+		}
+		sstmt, params, err = BindNamed(sstmt, qi.NamedParams, sds.db.Dialect())
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	sstmt, params, err = applyCriteria(sstmt, params, qi, sds.db.Dialect())
+	if err != nil {
+		return "", nil, err
+	}
+	return ExpandSliceParams(sstmt, params, sds.db.Dialect())
 }
 
 func (sds *RdbmsDataStore) GetJSON(writer io.Writer, qi QueryInput, jo OutputOptions) error {
-	rows, err := sds.FetchRows(nil, qi)
+	return sds.GetJSONContext(context.Background(), nil, writer, qi, jo)
+}
+
+func (sds *RdbmsDataStore) GetJSONContext(ctx context.Context, tx *Tx, writer io.Writer, qi QueryInput, jo OutputOptions) error {
+	rows, err := sds.FetchRowsContext(ctx, tx, qi)
 	if err != nil {
 		if qi.PanicOnErr {
 			panic(err)
@@ -150,11 +229,15 @@ func (sds *RdbmsDataStore) GetJSON(writer io.Writer, qi QueryInput, jo OutputOpt
 	}
 	defer rows.Close()
 
-	return RowsToJSON(writer, rows, jo.ToCamelCase, jo.IsArray, jo.DateFormat, jo.OmitNull)
+	return WriteJSON(writer, rows, jo)
 }
 
 func (sds *RdbmsDataStore) GetCSV(qi QueryInput, co OutputOptions) (string, error) {
-	rows, err := sds.FetchRows(nil, qi)
+	return sds.GetCSVContext(context.Background(), nil, qi, co)
+}
+
+func (sds *RdbmsDataStore) GetCSVContext(ctx context.Context, tx *Tx, qi QueryInput, co OutputOptions) (string, error) {
+	rows, err := sds.FetchRowsContext(ctx, tx, qi)
 	if err != nil {
 		log.Println(err)
 		return "", err
@@ -163,91 +246,607 @@ func (sds *RdbmsDataStore) GetCSV(qi QueryInput, co OutputOptions) (string, erro
 	return RowsToCSV(rows, co.ToCamelCase, co.DateFormat)
 }
 
+// writeRows streams the query results to the output writer in the output format.
+func (sds *RdbmsDataStore) writeRows(ctx context.Context, tx *Tx, qo QueryOutput, qi QueryInput) error {
+	rows, err := sds.FetchRowsContext(ctx, tx, qi)
+	if err != nil {
+		if qi.PanicOnErr {
+			panic(err)
+		}
+		return err
+	}
+	defer rows.Close()
+	switch qo.OutputFormat {
+	case JSON:
+		err = WriteJSON(qo.Writer, rows, qo.Options)
+	case NDJSON:
+		err = WriteNDJSON(qo.Writer, rows, qo.Options)
+	default:
+		err = WriteCSV(qo.Writer, rows, qo.Options)
+	}
+	if err != nil && qi.PanicOnErr {
+		panic(err)
+	}
+	return err
+}
+
 func (sds *RdbmsDataStore) InsertRecs(tx *Tx, input InsertInput) error {
+	_, err := sds.InsertRecsContext(context.Background(), tx, input)
+	return err
+}
+
+// InsertRecsContext inserts the input records and returns the total number of rows inserted.
+func (sds *RdbmsDataStore) InsertRecsContext(ctx context.Context, tx *Tx, input InsertInput) (int64, error) {
+	var rows int64
+	stmt, named, err := sds.insertStmt(input)
+	if err == nil {
+		input.named = named
+		recs := input.Records
+		rval := reflect.ValueOf(recs)
+		rrecs := reflect.Indirect(rval)
+		if input.Copy && rrecs.Kind() == reflect.Slice {
+			rows, err = sds.copyFrom(ctx, stmt, rrecs, input, tx)
+		} else if rrecs.Kind() == reflect.Slice {
+			if tx == nil {
+				rows, err = sds.insertNewTrans(ctx, stmt, rrecs, input)
+			} else {
+				rows, err = sds.insert(ctx, stmt, rrecs, input, tx)
+			}
+		} else {
+			rows, err = sds.insertRec(ctx, stmt, rval, input, tx)
+		}
+	}
+	if err != nil && input.PanicOnErr {
+		panic(err)
+	}
+	return rows, err
+}
+
+// copyFrom bulk loads the records with COPY, falling back to batch inserts
+// on stores that do not support it.
+func (sds *RdbmsDataStore) copyFrom(ctx context.Context, stmt string, rrecs reflect.Value, input InsertInput, tx *Tx) (int64, error) {
+	if input.Upsert || input.ReturnId || input.KeepIds {
+		return 0, errors.New("copy inserts do not support upserts, returning ids or keeping ids")
+	}
+	//copy loads the table columns directly so a statement would be silently ignored
+	if _, ok := input.Dataset.Commands()[insertkey]; ok || input.StatementKey != "" {
+		return 0, errors.New("copy inserts do not support statement keys or dataset insert statements")
+	}
+	rows, err := sds.db.CopyFrom(ctx, input.Dataset, rrecs, tx)
+	if errors.Is(err, ErrCopyNotSupported) {
+		input.Batch = true
+		if tx == nil {
+			return sds.insertNewTrans(ctx, stmt, rrecs, input)
+		}
+		return sds.insert(ctx, stmt, rrecs, input, tx)
+	}
+	return rows, err
+}
+
+// insertStmt returns the insert statement for the input along with the parameter names
+// of a dataset command that is bound by name.
+func (sds *RdbmsDataStore) insertStmt(input InsertInput) (string, []string, error) {
+	var stmt string
 	var err error
-	recs := input.Records
-	rval := reflect.ValueOf(recs)
-	rrecs := reflect.Indirect(rval)
-	if rrecs.Kind() == reflect.Slice {
+	if input.KeepIds && (input.Upsert || input.ReturnId) {
+		return "", nil, errors.New("inserts that keep ids do not support upserts or returning ids")
+	}
+	command := ""
+	if input.StatementKey != "" {
+		command = input.StatementKey
+	} else if _, ok := input.Dataset.Commands()[insertkey]; ok && !input.KeepIds && !input.Upsert {
+		command = insertkey
+	}
+	if command != "" {
+		cmd, ok := input.Dataset.Commands()[command]
+		if !ok {
+			return "", nil, fmt.Errorf("unable to find statement for %s: %s", input.Dataset.Entity(), command)
+		}
+		var names []string
+		stmt, names = namedCommand(cmd, input.Dataset, sds.db.Dialect())
+		if names != nil {
+			if input.ReturnId {
+				return "", nil, errors.New("returning ids is not supported for commands with named parameters")
+			}
+			return stmt, names, nil
+		}
+	} else if input.KeepIds {
+		stmt, err = ToInsertWithIds(input.Dataset, sds.db.Dialect())
+	} else if input.Upsert {
+		stmt, err = sds.db.UpsertStmt(input.Dataset, input.ConflictColumns, input.UpdateColumns)
+	} else {
+		stmt, err = sds.db.InsertStmt(input.Dataset)
+	}
+	if err != nil || !input.ReturnId {
+		return stmt, nil, err
+	}
+	if input.Upsert && sds.db.Dialect().ReturningInto {
+		return "", nil, errors.New("returning ids is not supported for upserts in this dialect")
+	}
+	stmt, err = ToReturning(input.Dataset, sds.db.Dialect(), stmt)
+	return stmt, nil, err
+}
+
+func (sds *RdbmsDataStore) UpdateRecs(tx *Tx, input UpdateInput) (int64, error) {
+	return sds.UpdateRecsContext(context.Background(), tx, input)
+}
+
+func (sds *RdbmsDataStore) UpdateRecsContext(ctx context.Context, tx *Tx, input UpdateInput) (int64, error) {
+	var rows int64
+	var err error
+	rrecs := reflect.Indirect(reflect.ValueOf(input.Records))
+	switch rrecs.Kind() {
+	case reflect.Slice:
 		if input.Batch {
-			err = sds.insertBatch(input.Dataset, rrecs, input.BatchSize)
+			if tx == nil {
+				rows, err = sds.updateBatchNewTrans(ctx, input.Dataset, rrecs, input.BatchSize)
+			} else {
+				rows, err = sds.updateBatch(ctx, input.Dataset, rrecs, input.BatchSize, tx)
+			}
 		} else {
 			if tx == nil {
-				err = sds.insertNewTrans(input.Dataset, rrecs)
+				rows, err = sds.updateNewTrans(ctx, input.Dataset, rrecs)
 			} else {
-				err = sds.insert(input.Dataset, rrecs, tx)
+				rows, err = sds.update(ctx, input.Dataset, rrecs, tx)
 			}
 		}
-	} else {
-		err = sds.db.Insert(input.Dataset, recs, tx)
+	case reflect.Struct:
+		cmd, names := sds.command(input.Dataset, updatekey)
+		rows, err = sds.updateRec(ctx, input.Dataset, cmd, names, input.Records, tx)
+	default:
+		err = errors.New("update requires a struct or a slice of struct records")
 	}
 	if err != nil && input.PanicOnErr {
 		panic(err)
 	}
-	return err
+	return rows, err
+}
+
+func (sds *RdbmsDataStore) DeleteRecs(tx *Tx, input DeleteInput) (int64, error) {
+	return sds.DeleteRecsContext(context.Background(), tx, input)
+}
+
+func (sds *RdbmsDataStore) DeleteRecsContext(ctx context.Context, tx *Tx, input DeleteInput) (int64, error) {
+	var rows int64
+	var err error
+	switch {
+	case input.Records != nil && (input.Suffix != "" || len(input.BindParams) > 0 || input.NamedParams != nil):
+		err = errors.New("delete cannot use both records and a suffix criteria")
+	case input.NamedParams != nil && len(input.BindParams) > 0:
+		err = errors.New("a delete cannot use both positional and named parameters")
+	case input.Records != nil:
+		rrecs := reflect.Indirect(reflect.ValueOf(input.Records))
+		if rrecs.Kind() == reflect.Slice && tx == nil {
+			err = sds.TransactionContext(ctx, func(tx Tx) {
+				var txerr error
+				rows, txerr = sds.delete(ctx, input.Dataset, rrecs, &tx)
+				if txerr != nil {
+					panic(txerr)
+				}
+			})
+			if err != nil {
+				rows = 0
+			}
+		} else {
+			rows, err = sds.delete(ctx, input.Dataset, rrecs, tx)
+		}
+	case input.Suffix != "":
+		var res ExecResult
+		stmt := fmt.Sprintf("delete from %s %s", input.Dataset.Entity(), input.Suffix)
+		params := input.BindParams
+		if input.NamedParams != nil {
+			stmt, params, err = BindNamed(stmt, input.NamedParams, sds.db.Dialect())
+		}
+		if err == nil {
+			stmt, params, err = ExpandSliceParams(stmt, params, sds.db.Dialect())
+		}
+		if err == nil {
+			res, err = sds.db.Execr(ctx, tx, stmt, params...)
+		}
+		if err == nil {
+			rows = res.RowsAffected()
+		}
+	default:
+		err = errors.New("delete requires records or a suffix criteria")
+	}
+	if err != nil && input.PanicOnErr {
+		panic(err)
+	}
+	return rows, err
 }
 
 func (sds *RdbmsDataStore) Exec(tx *Tx, stmt string, params ...interface{}) error {
-	return sds.db.Exec(tx, stmt, params...)
+	return sds.ExecContext(context.Background(), tx, stmt, params...)
+}
+
+func (sds *RdbmsDataStore) ExecContext(ctx context.Context, tx *Tx, stmt string, params ...interface{}) error {
+	stmt, params, err := sds.execStatement(stmt, params)
+	if err != nil {
+		return err
+	}
+	return sds.db.Exec(ctx, tx, stmt, params...)
 }
 
 func (sds *RdbmsDataStore) Execr(tx *Tx, stmt string, params ...interface{}) (ExecResult, error) {
-	return sds.db.Execr(tx, stmt, params...)
+	return sds.ExecrContext(context.Background(), tx, stmt, params...)
+}
+
+func (sds *RdbmsDataStore) ExecrContext(ctx context.Context, tx *Tx, stmt string, params ...interface{}) (ExecResult, error) {
+	stmt, params, err := sds.execStatement(stmt, params)
+	if err != nil {
+		return nil, err
+	}
+	return sds.db.Execr(ctx, tx, stmt, params...)
 }
 
 func (sds *RdbmsDataStore) MustExec(tx *Tx, stmt string, params ...interface{}) {
-	sds.db.MustExec(tx, stmt, params...)
+	sds.MustExecContext(context.Background(), tx, stmt, params...)
+}
+
+func (sds *RdbmsDataStore) MustExecContext(ctx context.Context, tx *Tx, stmt string, params ...interface{}) {
+	stmt, params, err := sds.execStatement(stmt, params)
+	if err != nil {
+		panic(err)
+	}
+	sds.db.MustExec(ctx, tx, stmt, params...)
 }
 
 func (sds *RdbmsDataStore) MustExecr(tx *Tx, stmt string, params ...interface{}) ExecResult {
-	return sds.db.MustExecr(tx, stmt, params...)
+	return sds.MustExecrContext(context.Background(), tx, stmt, params...)
+}
+
+func (sds *RdbmsDataStore) MustExecrContext(ctx context.Context, tx *Tx, stmt string, params ...interface{}) ExecResult {
+	stmt, params, err := sds.execStatement(stmt, params)
+	if err != nil {
+		panic(err)
+	}
+	return sds.db.MustExecr(ctx, tx, stmt, params...)
 }
 
-func (sds *RdbmsDataStore) insertNewTrans(ds DataSet, rrecs reflect.Value) error {
-	err := sds.Transaction(func(tx Tx) {
-		err := sds.insert(ds, rrecs, &tx)
+// execStatement binds the named parameters passed with Named and expands slice parameters.
+func (sds *RdbmsDataStore) execStatement(stmt string, params []interface{}) (string, []interface{}, error) {
+	for _, p := range params {
+		if named, ok := p.(NamedArgs); ok {
+			if len(params) > 1 {
+				return "", nil, errors.New("a statement cannot use both positional and named parameters")
+			}
+			var err error
+			stmt, params, err = BindNamed(stmt, named.params, sds.db.Dialect())
+			if err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	return ExpandSliceParams(stmt, params, sds.db.Dialect())
+}
+
+func (sds *RdbmsDataStore) insertNewTrans(ctx context.Context, stmt string, rrecs reflect.Value, input InsertInput) (int64, error) {
+	var rows int64
+	err := sds.TransactionContext(ctx, func(tx Tx) {
+		var err error
+		rows, err = sds.insert(ctx, stmt, rrecs, input, &tx)
 		if err != nil {
 			panic(err)
 		}
 	})
-	return err
+	if err != nil {
+		return 0, err
+	}
+	return rows, nil
 }
 
-func (sds *RdbmsDataStore) insert(ds DataSet, rrecs reflect.Value, tx *Tx) error {
+func (sds *RdbmsDataStore) insert(ctx context.Context, stmt string, rrecs reflect.Value, input InsertInput, tx *Tx) (int64, error) {
+	if input.Batch {
+		return sds.insertBatch(ctx, stmt, rrecs, input, tx)
+	}
+	var rows int64
 	for i := 0; i < rrecs.Len(); i++ {
-		err := sds.db.Insert(ds, rrecs.Index(i).Interface(), tx)
+		n, err := sds.insertRec(ctx, stmt, rrecs.Index(i), input, tx)
 		if err != nil {
 			log.Printf("Failed to insert: %s\n", err)
-			return err
+			return rows, err
 		}
+		rows += n
 	}
-	return nil
+	return rows, nil
 }
 
-func (sds *RdbmsDataStore) insertBatch(ds DataSet, rrecs reflect.Value, batchSize int) error {
+func (sds *RdbmsDataStore) insertRec(ctx context.Context, stmt string, rec reflect.Value, input InsertInput, tx *Tx) (int64, error) {
+	if input.KeepIds || input.named != nil {
+		params, err := insertParams(input, rec)
+		if err != nil {
+			return 0, err
+		}
+		res, err := sds.db.Execr(ctx, tx, stmt, params...)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected(), nil
+	}
+	if input.ReturnId {
+		recp, err := recordPointer(rec)
+		if err != nil {
+			return 0, err
+		}
+		return sds.db.InsertReturning(ctx, stmt, recp, tx)
+	}
+	return sds.db.Insert(ctx, stmt, rec.Interface(), tx)
+}
+
+// insertBatch queues the records and sends a batch every batchSize records.
+func (sds *RdbmsDataStore) insertBatch(ctx context.Context, stmt string, rrecs reflect.Value, input InsertInput, tx *Tx) (int64, error) {
+	batchSize := input.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
 	batch, err := sds.db.Batch()
 	if err != nil {
-		return err
+		return 0, err
+	}
+
+	returnInto := sds.db.Dialect().ReturningInto
+	var rows int64
+	var ids []interface{}
+	start := 0
+	for i := 0; i < rrecs.Len(); i++ {
+		rec := rrecs.Index(i)
+		params, err := insertParams(input, rec)
+		if err != nil {
+			return rows, &BatchError{i, err}
+		}
+		if input.ReturnId {
+			recp, err := recordPointer(rec)
+			if err != nil {
+				return rows, &BatchError{i, err}
+			}
+			id, err := StructIdPointer(recp)
+			if err != nil {
+				return rows, &BatchError{i, err}
+			}
+			if returnInto {
+				params = append(params, sql.Out{Dest: id})
+			} else {
+				ids = append(ids, id)
+			}
+		}
+		batch.Queue(stmt, params...)
+		if i-start+1 == batchSize || i == rrecs.Len()-1 {
+			n, err := sds.sendBatch(ctx, tx, batch, start, i-start+1, ids)
+			rows += n
+			if err != nil {
+				return rows, err
+			}
+			start = i + 1
+			ids = nil
+			batch, err = sds.db.Batch()
+			if err != nil {
+				return rows, err
+			}
+		}
 	}
+	return rows, nil
+}
+
+// insertParams returns the bind parameters of an insert record.
+func insertParams(input InsertInput, rec reflect.Value) ([]interface{}, error) {
+	if input.named != nil {
+		return recordValues(input.named, rec.Interface())
+	}
+	return structToIArray(rec.Interface(), input.KeepIds), nil
+}
+
+// recordPointer returns a pointer to a struct record so that generated ids can be set on it.
+func recordPointer(rec reflect.Value) (interface{}, error) {
+	if rec.Kind() == reflect.Pointer {
+		return rec.Interface(), nil
+	}
+	if !rec.CanAddr() {
+		return nil, errors.New("unable to return ids.  records must be passed as a pointer or a slice")
+	}
+	return rec.Addr().Interface(), nil
+}
 
-	stmt, err := sds.db.InsertStmt(ds)
+func isNoRows(err error) bool {
+	return errors.Is(err, pgx.ErrNoRows) || errors.Is(err, sql.ErrNoRows)
+}
+
+func (sds *RdbmsDataStore) delete(ctx context.Context, ds DataSet, rrecs reflect.Value, tx *Tx) (int64, error) {
+	stmt, err := sds.db.DeleteStmt(ds)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	cmd, names := sds.command(ds, deletekey)
+	if names != nil {
+		stmt = cmd
+	}
+	if rrecs.Kind() != reflect.Slice {
+		return sds.deleteRec(ctx, stmt, names, rrecs.Interface(), tx)
+	}
+	var rows int64
+	for i := 0; i < rrecs.Len(); i++ {
+		n, err := sds.deleteRec(ctx, stmt, names, rrecs.Index(i).Interface(), tx)
+		if err != nil {
+			log.Printf("Failed to delete: %s\n", err)
+			return rows, err
+		}
+		rows += n
+	}
+	return rows, nil
+}
+
+// deleteRec deletes rec by its id, or by the named parameters of a dataset delete command.
+func (sds *RdbmsDataStore) deleteRec(ctx context.Context, stmt string, names []string, rec interface{}, tx *Tx) (int64, error) {
+	var params []interface{}
+	var err error
+	if names != nil {
+		params, err = recordValues(names, rec)
+	} else {
+		var id interface{}
+		id, err = StructIdValue(rec)
+		params = []interface{}{id}
+	}
+	if err != nil {
+		return 0, err
+	}
+	res, err := sds.db.Execr(ctx, tx, stmt, params...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+func (sds *RdbmsDataStore) updateNewTrans(ctx context.Context, ds DataSet, rrecs reflect.Value) (int64, error) {
+	var rows int64
+	err := sds.TransactionContext(ctx, func(tx Tx) {
+		var err error
+		rows, err = sds.update(ctx, ds, rrecs, &tx)
+		if err != nil {
+			panic(err)
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rows, nil
+}
+
+// updateBatchNewTrans sends the batches in a single transaction so a failing
+// batch rolls back the batches that were already sent.
+func (sds *RdbmsDataStore) updateBatchNewTrans(ctx context.Context, ds DataSet, rrecs reflect.Value, batchSize int) (int64, error) {
+	var rows int64
+	err := sds.TransactionContext(ctx, func(tx Tx) {
+		var err error
+		rows, err = sds.updateBatch(ctx, ds, rrecs, batchSize, &tx)
+		if err != nil {
+			panic(err)
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return rows, nil
+}
+
+func (sds *RdbmsDataStore) update(ctx context.Context, ds DataSet, rrecs reflect.Value, tx *Tx) (int64, error) {
+	cmd, names := sds.command(ds, updatekey)
+	var rows int64
+	for i := 0; i < rrecs.Len(); i++ {
+		n, err := sds.updateRec(ctx, ds, cmd, names, rrecs.Index(i).Interface(), tx)
+		if err != nil {
+			log.Printf("Failed to update: %s\n", err)
+			return rows, err
+		}
+		rows += n
+	}
+	return rows, nil
+}
+
+// updateRec updates rec with the dataset update command when it is bound by name and
+// with the store update otherwise.
+func (sds *RdbmsDataStore) updateRec(ctx context.Context, ds DataSet, cmd string, names []string, rec interface{}, tx *Tx) (int64, error) {
+	if names == nil {
+		return sds.db.Update(ctx, ds, rec, tx)
+	}
+	params, err := recordValues(names, rec)
+	if err != nil {
+		return 0, err
+	}
+	res, err := sds.db.Execr(ctx, tx, cmd, params...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected(), nil
+}
+
+// command returns the dataset command stored under key with the names of its parameters
+// when it is bound by name.  names is nil when there is no command or it is bound by position.
+func (sds *RdbmsDataStore) command(ds DataSet, key string) (string, []string) {
+	cmd, ok := ds.Commands()[key]
+	if !ok {
+		return "", nil
+	}
+	return namedCommand(cmd, ds, sds.db.Dialect())
+}
+
+func (sds *RdbmsDataStore) updateBatch(ctx context.Context, ds DataSet, rrecs reflect.Value, batchSize int, tx *Tx) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	stmt, err := sds.db.UpdateStmt(ds)
+	if err != nil {
+		return 0, err
+	}
+	cmd, names := sds.command(ds, updatekey)
+	if names != nil {
+		stmt = cmd
+	}
+
+	batch, err := sds.db.Batch()
+	if err != nil {
+		return 0, err
 	}
 
+	var rows int64
+	queued := 0
 	for i := 0; i < rrecs.Len(); i++ {
-		rec := rrecs.Index(i).Interface()
-		batch.Queue(stmt, StructToIArray(rec)...)
-		if i >= batchSize {
-			sds.db.SendBatch(batch)
+		var params []interface{}
+		if names != nil {
+			params, err = recordValues(names, rrecs.Index(i).Interface())
+		} else {
+			params, err = StructToUpdateIArray(rrecs.Index(i).Interface())
+		}
+		if err != nil {
+			return rows, err
+		}
+		batch.Queue(stmt, params...)
+		queued++
+		if queued == batchSize || i == rrecs.Len()-1 {
+			n, err := sds.sendBatch(ctx, tx, batch, i-queued+1, queued, nil)
+			rows += n
+			if err != nil {
+				return rows, err
+			}
 			batch, err = sds.db.Batch()
 			if err != nil {
-				return err
+				return rows, err
 			}
+			queued = 0
+		}
+	}
+	return rows, nil
+}
+
+// sendBatch sends the batch and reads the result of each queued statement, returning
+// the total rows affected.  start is the index of the first queued record and is used
+// to report the failing record in a BatchError.  If ids are supplied, the id returned
+// by each statement is scanned into the matching id pointer.
+func (sds *RdbmsDataStore) sendBatch(ctx context.Context, tx *Tx, batch Batch, start int, queued int, ids []interface{}) (int64, error) {
+	br := sds.db.SendBatch(ctx, tx, batch)
+	var rows int64
+	for i := 0; i < queued; i++ {
+		var err error
+		if ids != nil {
+			err = br.QueryRow().Scan(ids[i])
+			if err == nil {
+				rows++
+			} else if isNoRows(err) {
+				err = nil
+			}
+		} else {
+			var res ExecResult
+			res, err = br.Exec()
+			if err == nil {
+				rows += res.RowsAffected()
+			}
+		}
+		if err != nil {
+			br.Close()
+			return rows, &BatchError{start + i, err}
 		}
 	}
-	sds.db.SendBatch(batch)
-	return nil
+	return rows, br.Close()
 }
 
 func (sds *RdbmsDataStore) Select(stmt ...string) *FluentSelect {
@@ -260,8 +859,10 @@ func (sds *RdbmsDataStore) Select(stmt ...string) *FluentSelect {
 			Statement: stmts,
 		},
 		store: sds,
+		ctx:   context.Background(),
 	}
 	s.CamelCase(true)
+	s.CsvHeader(true)
 	return &s
 }
 
@@ -269,6 +870,34 @@ func (sds *RdbmsDataStore) Insert(ds DataSet) *FluentInsert {
 	fi := FluentInsert{
 		ds:    ds,
 		store: sds,
+		ctx:   context.Background(),
+	}
+	return &fi
+}
+
+func (sds *RdbmsDataStore) Update(ds DataSet) *FluentUpdate {
+	fu := FluentUpdate{
+		ds:    ds,
+		store: sds,
+		ctx:   context.Background(),
+	}
+	return &fu
+}
+
+func (sds *RdbmsDataStore) Delete(ds DataSet) *FluentDelete {
+	fd := FluentDelete{
+		ds:    ds,
+		store: sds,
+		ctx:   context.Background(),
+	}
+	return &fd
+}
+
+func (sds *RdbmsDataStore) Import(ds DataSet) *FluentImport {
+	fi := FluentImport{
+		ds:    ds,
+		store: sds,
+		ctx:   context.Background(),
 	}
 	return &fi
 }
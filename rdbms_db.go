@@ -1,17 +1,29 @@
 package goquery
 
+import (
+	"context"
+	"reflect"
+)
+
 type RdbmsDb interface {
 	Connection() interface{}
-	Transaction() (Tx, error)
-	Select(dest interface{}, tx *Tx, stmt string, params ...interface{}) error
-	Get(dest interface{}, tx *Tx, stmt string, params ...interface{}) error
-	Query(tx *Tx, stmt string, params ...interface{}) (Rows, error)
-	Insert(ds DataSet, rec interface{}, tx *Tx) error
+	Dialect() DbDialect
+	Transaction(ctx context.Context) (Tx, error)
+	Select(ctx context.Context, dest interface{}, tx *Tx, stmt string, params ...interface{}) error
+	Get(ctx context.Context, dest interface{}, tx *Tx, stmt string, params ...interface{}) error
+	Query(ctx context.Context, tx *Tx, stmt string, params ...interface{}) (Rows, error)
+	Insert(ctx context.Context, stmt string, rec interface{}, tx *Tx) (int64, error)
+	InsertReturning(ctx context.Context, stmt string, rec interface{}, tx *Tx) (int64, error)
 	InsertStmt(ds DataSet) (string, error)
-	Exec(tx *Tx, stmt string, params ...interface{}) error
-	Execr(tx *Tx, stmt string, params ...interface{}) (ExecResult, error)
-	MustExec(tx *Tx, stmt string, params ...interface{})
-	MustExecr(tx *Tx, stmt string, params ...interface{}) ExecResult
+	UpsertStmt(ds DataSet, conflict []string, update []string) (string, error)
+	CopyFrom(ctx context.Context, ds DataSet, recs reflect.Value, tx *Tx) (int64, error)
+	Update(ctx context.Context, ds DataSet, rec interface{}, tx *Tx) (int64, error)
+	UpdateStmt(ds DataSet) (string, error)
+	DeleteStmt(ds DataSet) (string, error)
+	Exec(ctx context.Context, tx *Tx, stmt string, params ...interface{}) error
+	Execr(ctx context.Context, tx *Tx, stmt string, params ...interface{}) (ExecResult, error)
+	MustExec(ctx context.Context, tx *Tx, stmt string, params ...interface{})
+	MustExecr(ctx context.Context, tx *Tx, stmt string, params ...interface{}) ExecResult
 	Batch() (Batch, error)
-	SendBatch(batch Batch) BatchResult
+	SendBatch(ctx context.Context, tx *Tx, batch Batch) BatchResult
 }
@@ -0,0 +1,236 @@
+package goquery
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ExpandSliceParams expands parameters bound to slices inside an in list, such as
+// "id in ($1)" or "id in (:1)", into one bind parameter per slice element in the
+// dialect bind format.  An empty slice is replaced with a subquery that returns no
+// rows, so "in" matches nothing and "not in" matches everything.  Slices bound
+// anywhere else, such as "id = any($1)" or an array column value, are left as is.
+// A slice must be the only item of its in list, and may not have more elements
+// than the dialect MaxInList.  :name placeholders are only recognized for dialects
+// that bind with them.
+func ExpandSliceParams(stmt string, params []interface{}, dialect DbDialect) (string, []interface{}, error) {
+	if !hasSliceParam(params) {
+		return stmt, params, nil
+	}
+	colonBinds := strings.HasPrefix(dialect.Bind("p1", 0), ":")
+	var builder strings.Builder
+	var args []interface{}
+	numbered := make(map[int]int)
+	next := 0
+	prev := rune(0)
+	for i := 0; i < len(stmt); {
+		if end := skipNonCode(stmt, i); end > i {
+			builder.WriteString(stmt[i:end])
+			i = end
+			prev = ' '
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(stmt[i:])
+		if r == ':' && strings.HasPrefix(stmt[i:], "::") {
+			builder.WriteString("::")
+			i += 2
+			prev = ':'
+			continue
+		}
+
+		//$n placeholders reference a parameter by position.  :name and :n
+		//placeholders are bound in the order they appear
+		param, token := -1, ""
+		if !isIdentRune(prev) {
+			switch r {
+			case '$':
+				if digits := leadingDigits(stmt[i+1:]); digits != "" {
+					n, _ := strconv.Atoi(digits)
+					param, token = n-1, stmt[i:i+1+len(digits)]
+				}
+			case ':':
+				if !colonBinds {
+					break
+				}
+				if name := placeholderName(stmt[i+1:]); name != "" {
+					param, token = next, stmt[i:i+1+len(name)]
+					next++
+				}
+			}
+		}
+		if token == "" {
+			builder.WriteRune(r)
+			i += size
+			prev = r
+			continue
+		}
+		if param < 0 || param >= len(params) {
+			return "", nil, fmt.Errorf("statement parameter %s does not have a value", token)
+		}
+
+		val := params[param]
+		items := 0
+		if isSliceParam(val) {
+			items = inListItems(stmt, i, i+len(token))
+		}
+		switch {
+		case items > 1:
+			return "", nil, fmt.Errorf("slice parameter %s must be the only item of its in list", token)
+		case items == 1:
+			if dialect.MaxInList > 0 && reflect.ValueOf(val).Len() > dialect.MaxInList {
+				return "", nil, fmt.Errorf("slice parameter %s has %d elements.  in lists are limited to %d elements in this dialect",
+					token, reflect.ValueOf(val).Len(), dialect.MaxInList)
+			}
+			binds, err := expandSlice(reflect.ValueOf(val), len(args), dialect)
+			if err != nil {
+				return "", nil, err
+			}
+			builder.WriteString(binds)
+			for j := 0; j < reflect.ValueOf(val).Len(); j++ {
+				args = append(args, reflect.ValueOf(val).Index(j).Interface())
+			}
+		case token[0] == '$':
+			n, ok := numbered[param]
+			if !ok {
+				n = len(args)
+				numbered[param] = n
+				args = append(args, val)
+			}
+			builder.WriteString(dialect.Bind(fmt.Sprintf("p%d", n+1), n))
+		default:
+			builder.WriteString(token)
+			args = append(args, val)
+		}
+		i += len(token)
+		prev = 'a'
+	}
+	return builder.String(), args, nil
+}
+
+// expandSlice returns the bind parameters for each element of slice, numbered from start,
+// or the dialect empty list subquery for an empty slice.
+func expandSlice(slice reflect.Value, start int, dialect DbDialect) (string, error) {
+	if slice.Len() == 0 {
+		//an untyped slice such as []interface{} has no column type to cast to
+		if dialect.EmptyList == nil || dialect.ColumnType == nil || slice.Type().Elem().Kind() == reflect.Interface {
+			return "null", nil
+		}
+		colType, err := dialect.ColumnType(slice.Type().Elem())
+		if err != nil {
+			return "", fmt.Errorf("unable to expand an empty slice parameter: %s", err)
+		}
+		return dialect.EmptyList(colType), nil
+	}
+	binds := make([]string, slice.Len())
+	for j := range binds {
+		n := start + j
+		binds[j] = dialect.Bind(fmt.Sprintf("p%d", n+1), n)
+	}
+	return strings.Join(binds, ","), nil
+}
+
+func hasSliceParam(params []interface{}) bool {
+	for _, p := range params {
+		if isSliceParam(p) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSliceParam is true for slices and arrays other than byte slices and driver values.
+func isSliceParam(p interface{}) bool {
+	if p == nil {
+		return false
+	}
+	if _, ok := p.(driver.Valuer); ok {
+		return false
+	}
+	typ := reflect.TypeOf(p)
+	if typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array {
+		return false
+	}
+	return typ.Elem().Kind() != reflect.Uint8
+}
+
+// inListItems returns the number of items in the in list that has the placeholder at
+// stmt[start:end] as one of its items, or 0 when the placeholder is not an in list item.
+func inListItems(stmt string, start int, end int) int {
+	open, depth := -1, 0
+	for j := start - 1; j >= 0 && open < 0; j-- {
+		switch stmt[j] {
+		case ')':
+			depth++
+		case '(':
+			if depth == 0 {
+				open = j
+			}
+			depth--
+		}
+	}
+	if open < 0 {
+		return 0
+	}
+	before := strings.TrimRightFunc(stmt[:open], unicode.IsSpace)
+	if len(before) < 2 || !strings.EqualFold(before[len(before)-2:], "in") {
+		return 0
+	}
+	if r, _ := utf8.DecodeLastRuneInString(before[:len(before)-2]); isIdentRune(r) {
+		return 0
+	}
+
+	//split the list on top level commas and check that the placeholder is a whole item
+	items := 1
+	item := false
+	itemStart := open + 1
+	depth = 0
+	for j := open + 1; j < len(stmt); j++ {
+		switch stmt[j] {
+		case '(':
+			depth++
+		case ')', ',':
+			if depth > 0 {
+				if stmt[j] == ')' {
+					depth--
+				}
+				continue
+			}
+			if itemStart <= start && j >= end {
+				item = strings.TrimSpace(stmt[itemStart:start]) == "" && strings.TrimSpace(stmt[end:j]) == ""
+			}
+			if stmt[j] == ')' {
+				if !item {
+					return 0
+				}
+				return items
+			}
+			items++
+			itemStart = j + 1
+		}
+	}
+	return 0
+}
+
+func leadingDigits(s string) string {
+	for i, r := range s {
+		if !unicode.IsDigit(r) {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// placeholderName returns the name or number of a :name or :n placeholder.
+func placeholderName(s string) string {
+	for i, r := range s {
+		if !isIdentRune(r) {
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,99 @@
+package goquery
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandSliceParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  DbDialect
+		stmt     string
+		params   []interface{}
+		want     string
+		wantArgs []interface{}
+	}{
+		{
+			name:     "pg",
+			dialect:  pgDialect,
+			stmt:     "select * from t where id in ($2) and name=$1 and code not in ( $3 ) or name=$1",
+			params:   []interface{}{"a", []int{1, 2}, []string{"x"}},
+			want:     "select * from t where id in ($1,$2) and name=$3 and code not in ( $4 ) or name=$3",
+			wantArgs: []interface{}{1, 2, "a", "x"},
+		},
+		{
+			name:     "pg any",
+			dialect:  pgDialect,
+			stmt:     "select * from t where id = any($1) and data=$2",
+			params:   []interface{}{[]int{1, 2}, []byte("x")},
+			want:     "select * from t where id = any($1) and data=$2",
+			wantArgs: []interface{}{[]int{1, 2}, []byte("x")},
+		},
+		{
+			name:     "pg empty",
+			dialect:  pgDialect,
+			stmt:     "select * from t where id not in ($1)",
+			params:   []interface{}{[]int32{}},
+			want:     "select * from t where id not in (select cast(null as integer) where false)",
+			wantArgs: nil,
+		},
+		{
+			name:     "pg empty untyped",
+			dialect:  pgDialect,
+			stmt:     "select * from t where id not in ($1)",
+			params:   []interface{}{[]interface{}{}},
+			want:     "select * from t where id not in (null)",
+			wantArgs: nil,
+		},
+		{
+			name:     "pg colon",
+			dialect:  pgDialect,
+			stmt:     "select * from t where id in ($1) and name = :name and ts > $2::date",
+			params:   []interface{}{[]int{1, 2}, "2020-01-01"},
+			want:     "select * from t where id in ($1,$2) and name = :name and ts > $3::date",
+			wantArgs: []interface{}{1, 2, "2020-01-01"},
+		},
+		{
+			name:     "pg subquery",
+			dialect:  pgDialect,
+			stmt:     "select * from t where id in (select id from u where tags = $1 and coalesce(a, b) = 1)",
+			params:   []interface{}{[]string{"x"}},
+			want:     "select * from t where id in (select id from u where tags = $1 and coalesce(a, b) = 1)",
+			wantArgs: []interface{}{[]string{"x"}},
+		},
+		{
+			name:     "oracle",
+			dialect:  oracleDialect,
+			stmt:     "select * from t where name=:name and id IN (:ids) and x=':ids'",
+			params:   []interface{}{"a", []int{1, 2}},
+			want:     "select * from t where name=:name and id IN (:p2,:p3) and x=':ids'",
+			wantArgs: []interface{}{"a", 1, 2},
+		},
+	}
+	for _, test := range tests {
+		got, args, err := ExpandSliceParams(test.stmt, test.params, test.dialect)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: Got %s want %s", test.name, got, test.want)
+		}
+		if !reflect.DeepEqual(args, test.wantArgs) {
+			t.Errorf("%s: Got args %v want %v", test.name, args, test.wantArgs)
+		}
+	}
+
+	if _, _, err := ExpandSliceParams("select * from t where id in ($2)", []interface{}{[]int{1}}, pgDialect); err == nil {
+		t.Error("expected an error for a parameter without a value")
+	}
+	if _, _, err := ExpandSliceParams("select * from t where id in ($1, $2)", []interface{}{[]int{1}, 2}, pgDialect); err == nil {
+		t.Error("expected an error for a slice parameter in a list with other items")
+	}
+	if _, _, err := ExpandSliceParams("select * from t where id in (:ids)", []interface{}{make([]int, 1001)}, oracleDialect); err == nil {
+		t.Error("expected an error for a slice parameter longer than the oracle in list limit")
+	}
+	if _, _, err := ExpandSliceParams("select * from t where id in (:ids)", []interface{}{make([]int, 1000)}, oracleDialect); err != nil {
+		t.Error(err)
+	}
+}
@@ -78,59 +78,176 @@ func ToSelectStmtDepricated(ds DataSet) string {
 }
 
 func ToInsert(ds DataSet, dialect DbDialect) (string, error) {
-	var fieldBuilder strings.Builder
-	var bindBuilder strings.Builder
+	fields, binds, err := insertFieldsAndBinds(ds, dialect)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("insert into %s (%s) values (%s)", ds.Entity(), strings.Join(fields, ","), strings.Join(binds, ",")), nil
+}
+
+// ToInsertWithIds generates an insert statement that binds every db tagged field, including
+// dbid fields, so records are written with the ids they already have rather than generated ids.
+// The binds are in the order of the values returned for the record with its ids.
+func ToInsertWithIds(ds DataSet, dialect DbDialect) (string, error) {
+	fields := typeToColumns(reflect.TypeOf(ds.Fields()), true)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("no db tagged fields in %s", ds.Entity())
+	}
+	binds := make([]string, len(fields))
+	for i, field := range fields {
+		binds[i] = dialect.Bind(field, i)
+	}
+	return fmt.Sprintf("insert into %s (%s) values (%s)", ds.Entity(), strings.Join(fields, ","), strings.Join(binds, ",")), nil
+}
+
+// ToUpsert generates an insert statement that resolves conflicts on the conflict columns
+// by updating the update columns.  If no update columns are given conflicting rows are
+// left unchanged.  The statement is rendered by the dialect (ON CONFLICT for postgres,
+// MERGE for oracle) and uses the same bind parameters as ToInsert.
+func ToUpsert(ds DataSet, dialect DbDialect, conflict []string, update []string) (string, error) {
+	if dialect.Upsert == nil {
+		return "", errors.New("upsert is not supported by this dialect")
+	}
+	fields, binds, err := insertFieldsAndBinds(ds, dialect)
+	if err != nil {
+		return "", err
+	}
+	tags := dbFieldIndexes(reflect.TypeOf(ds.Fields()))
+	for _, col := range conflict {
+		if _, ok := tags[col]; !ok {
+			return "", fmt.Errorf("invalid upsert conflict column %q.  columns must be db tagged fields of %s", col, ds.Entity())
+		}
+	}
+	for _, col := range update {
+		if _, ok := tags[col]; !ok {
+			return "", fmt.Errorf("invalid upsert update column %q.  columns must be db tagged fields of %s", col, ds.Entity())
+		}
+		for _, c := range conflict {
+			if c == col {
+				return "", fmt.Errorf("invalid upsert update column %q.  conflict columns cannot be updated", col)
+			}
+		}
+	}
+	return dialect.Upsert(ds.Entity(), fields, binds, conflict, update)
+}
+
+// insertFieldsAndBinds returns the insert columns for a dataset along with the value
+// expression for each column.  Sequence ids use the dialect sequence expression and
+// AUTOINCREMENT ids are left to the database.
+func insertFieldsAndBinds(ds DataSet, dialect DbDialect) ([]string, []string, error) {
+	var fields []string
+	var binds []string
 	typ := reflect.TypeOf(ds.Fields())
 	fieldNum := typ.NumField()
-	fieldcount := 0
 	paramcount := 0
 	for i := 0; i < fieldNum; i++ {
 		if dbfield, ok := typ.Field(i).Tag.Lookup("db"); ok && dbfield != "_" {
-			if fieldcount > 0 {
-				fieldBuilder.WriteRune(',')
-				bindBuilder.WriteRune(',')
-			}
 			if idtype, ok := typ.Field(i).Tag.Lookup("dbid"); ok {
 				if idtype != "AUTOINCREMENT" {
 					if idsequence, ok := typ.Field(i).Tag.Lookup("idsequence"); ok {
-						fieldBuilder.WriteString(dbfield)
-						bindBuilder.WriteString(dialect.Seq(idsequence))
-						fieldcount++
+						fields = append(fields, dbfield)
+						binds = append(binds, dialect.Seq(idsequence))
 					} else {
-						return "", errors.New("invalid id.  sequence type must have an 'idsequence' tag")
+						return nil, nil, errors.New("invalid id.  sequence type must have an 'idsequence' tag")
 					}
 				}
 			} else {
-				fieldBuilder.WriteString(dbfield)
-				bindBuilder.WriteString(dialect.Bind(dbfield, paramcount))
-				fieldcount++
+				fields = append(fields, dbfield)
+				binds = append(binds, dialect.Bind(dbfield, paramcount))
 				paramcount++
 			}
 		}
 	}
-	return fmt.Sprintf("insert into %s (%s) values (%s)", ds.Entity(), fieldBuilder.String(), bindBuilder.String()), nil
+	return fields, binds, nil
 }
 
+// ToReturning appends the dialect returning clause for the dataset id field to an insert statement.
+func ToReturning(ds DataSet, dialect DbDialect, stmt string) (string, error) {
+	if dialect.Returning == nil {
+		return "", errors.New("returning ids is not supported by this dialect")
+	}
+	idfield := IdField(ds)
+	if idfield == "" {
+		return "", errors.New("invalid returning id.  dataset fields must have a 'dbid' tag")
+	}
+	return stmt + dialect.Returning(idfield), nil
+}
+
+// ToUpdate generates an update statement using bindTemplateFunction for the bind
+// parameters.  An empty statement is returned when the dataset fields do not have a
+// dbid tag.  Use ToUpdateStmt to generate an update for a dialect.
 func ToUpdate(ds DataSet, bindTemplateFunction BindParamTemplateFunction) string {
+	stmt, err := ToUpdateStmt(ds, DbDialect{Bind: bindTemplateFunction})
+	if err != nil {
+		return ""
+	}
+	return stmt
+}
+
+// ToUpdateStmt generates an update statement for the dataset that sets every db tagged
+// field and uses the dbid tagged field as the criteria.  Bind parameters are numbered
+// in the same order that StructToUpdateIArray returns values.
+func ToUpdateStmt(ds DataSet, dialect DbDialect) (string, error) {
 	var fieldsBuilder strings.Builder
-	var criteria string
+	var idfield string
 	typ := reflect.TypeOf(ds.Fields())
 	fieldNum := typ.NumField()
-	field := 0
+	paramcount := 0
 	for i := 0; i < fieldNum; i++ {
-		if tagval, ok := typ.Field(i).Tag.Lookup("db"); ok && tagval != "_" {
-			if field > 0 {
+		if tagval, ok := typ.Field(i).Tag.Lookup("db"); ok && isDbField(tagval) {
+			if _, ok := typ.Field(i).Tag.Lookup("dbid"); ok {
+				idfield = tagval
+				continue
+			}
+			if paramcount > 0 {
 				fieldsBuilder.WriteRune(',')
 			}
-			if _, ok := typ.Field(i).Tag.Lookup("dbid"); ok {
-				criteria = fmt.Sprintf(" where %s = %s", tagval, bindTemplateFunction(tagval, field))
-				continue //skip id field
+			fieldsBuilder.WriteString(fmt.Sprintf("%s = %s", tagval, dialect.Bind(tagval, paramcount)))
+			paramcount++
+		}
+	}
+	if idfield == "" {
+		return "", errors.New("invalid update.  dataset fields must have a 'dbid' tag")
+	}
+	return fmt.Sprintf("update %s set %s where %s = %s", ds.Entity(), fieldsBuilder.String(), idfield, dialect.Bind(idfield, paramcount)), nil
+}
+
+// ToDelete generates a delete statement for the dataset using the dbid tagged field as the criteria.
+func ToDelete(ds DataSet, dialect DbDialect) (string, error) {
+	idfield := IdField(ds)
+	if idfield == "" {
+		return "", errors.New("invalid delete.  dataset fields must have a 'dbid' tag")
+	}
+	return fmt.Sprintf("delete from %s where %s = %s", ds.Entity(), idfield, dialect.Bind(idfield, 0)), nil
+}
+
+// ToCreateTable generates a create table statement for the dataset from the db tagged
+// fields of its TableFields, using the dialect to map go types to column types.
+// The dbid tagged field is used as the primary key.
+func ToCreateTable(ds DataSet, dialect DbDialect) (string, error) {
+	if dialect.ColumnType == nil {
+		return "", errors.New("create table is not supported by this dialect")
+	}
+	typ := reflect.TypeOf(ds.Fields())
+	var columns []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if tagval, ok := field.Tag.Lookup("db"); ok && isDbField(tagval) {
+			coltype, err := dialect.ColumnType(field.Type)
+			if err != nil {
+				return "", fmt.Errorf("invalid column %s: %s", tagval, err)
 			}
-			fieldsBuilder.WriteString(fmt.Sprintf("%s = %s", tagval, bindTemplateFunction(tagval, field)))
-			field++
+			column := fmt.Sprintf("%s %s", tagval, coltype)
+			if idtype, ok := field.Tag.Lookup("dbid"); ok {
+				if idtype == "AUTOINCREMENT" {
+					column += " generated by default as identity"
+				}
+				column += " primary key"
+			}
+			columns = append(columns, column)
 		}
 	}
-	return fmt.Sprintf("update %s set %s %s", ds.Entity(), fieldsBuilder.String(), criteria)
+	return fmt.Sprintf("create table %s (%s)", ds.Entity(), strings.Join(columns, ",")), nil
 }
 
 func IdField(ds DataSet) string {
@@ -0,0 +1,207 @@
+package goquery
+
+import "testing"
+
+type generatorTest struct {
+	ID      int32   `db:"id" dbid:"SEQUENCE" idsequence:"gen_id_seq"`
+	Name    string  `db:"name"`
+	Comment *string `db:"comment"`
+	Ignored string
+}
+
+var generatorTbl TableDataSet = TableDataSet{
+	Name:        "gen_test",
+	Schema:      "test",
+	TableFields: generatorTest{},
+}
+
+func TestToUpdate(t *testing.T) {
+	want := "update test.gen_test set name = $1,comment = $2 where id = $3"
+	stmt, err := ToUpdateStmt(&generatorTbl, pgDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+
+	want = "update test.gen_test set name = :name,comment = :comment where id = :id"
+	stmt, _ = ToUpdateStmt(&generatorTbl, oracleDialect)
+	if stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+
+	noId := TableDataSet{Name: "no_id", TableFields: FishingSpot{}}
+	if _, err := ToUpdateStmt(&noId, pgDialect); err == nil {
+		t.Error("Expected an error for a dataset without a dbid field")
+	}
+
+	if stmt := ToUpdate(&generatorTbl, pgDialect.Bind); stmt != "update test.gen_test set name = $1,comment = $2 where id = $3" {
+		t.Errorf("Got %s from ToUpdate", stmt)
+	}
+	if stmt := ToUpdate(&noId, pgDialect.Bind); stmt != "" {
+		t.Errorf("Got %s from ToUpdate want an empty statement", stmt)
+	}
+}
+
+func TestStructToUpdateIArray(t *testing.T) {
+	params, err := StructToUpdateIArray(&generatorTest{ID: 7, Name: "seven"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(params) != 3 || params[0] != "seven" || params[1] != nil || params[2] != int32(7) {
+		t.Errorf("Got %v want [seven <nil> 7]", params)
+	}
+}
+
+func TestToDelete(t *testing.T) {
+	want := "delete from test.gen_test where id = $1"
+	stmt, err := ToDelete(&generatorTbl, pgDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+}
+
+func TestToUpsert(t *testing.T) {
+	want := "insert into test.gen_test (id,name,comment) values (nextval('gen_id_seq'),$1,$2) on conflict (name) do update set comment = excluded.comment"
+	stmt, err := ToUpsert(&generatorTbl, pgDialect, []string{"name"}, []string{"comment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+
+	want = "insert into test.gen_test (id,name,comment) values (nextval('gen_id_seq'),$1,$2) on conflict (name) do nothing"
+	stmt, _ = ToUpsert(&generatorTbl, pgDialect, []string{"name"}, nil)
+	if stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+
+	want = "merge into test.gen_test d using (select :name name,:comment comment from dual) s on (d.name = s.name)" +
+		" when matched then update set d.comment = s.comment" +
+		" when not matched then insert (id,name,comment) values (gen_id_seq.nextval,s.name,s.comment)"
+	stmt, err = ToUpsert(&generatorTbl, oracleDialect, []string{"name"}, []string{"comment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+
+	if _, err := ToUpsert(&generatorTbl, oracleDialect, nil, nil); err == nil {
+		t.Error("Expected an error for a merge without conflict columns")
+	}
+	if _, err := ToUpsert(&generatorTbl, oracleDialect, []string{"id"}, []string{"name"}); err == nil {
+		t.Error("Expected an error for a merge on a sequence id")
+	}
+
+	invalid := []struct {
+		conflict []string
+		update   []string
+	}{
+		{[]string{"name"}, []string{"name", "comment"}},
+		{[]string{"name) do nothing; --"}, nil},
+		{[]string{"name"}, []string{"Ignored"}},
+	}
+	for _, test := range invalid {
+		for _, dialect := range []DbDialect{pgDialect, oracleDialect} {
+			if _, err := ToUpsert(&generatorTbl, dialect, test.conflict, test.update); err == nil {
+				t.Errorf("Expected an error for conflict %v and update %v", test.conflict, test.update)
+			}
+		}
+	}
+}
+
+func TestToReturning(t *testing.T) {
+	insert, _ := ToInsert(&generatorTbl, pgDialect)
+	stmt, err := ToReturning(&generatorTbl, pgDialect, insert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := insert + " returning id"; stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+
+	insert, _ = ToInsert(&generatorTbl, oracleDialect)
+	stmt, _ = ToReturning(&generatorTbl, oracleDialect, insert)
+	if want := insert + " returning id into :id"; stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+}
+
+func TestToCreateTable(t *testing.T) {
+	want := "create table test.gen_test (id integer primary key,name text,comment text)"
+	stmt, err := ToCreateTable(&generatorTbl, pgDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+
+	want = "create table test.gen_test (id number(10) primary key,name varchar2(4000),comment varchar2(4000))"
+	stmt, _ = ToCreateTable(&generatorTbl, oracleDialect)
+	if stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+
+	autoTbl := TableDataSet{Name: "auto_test", TableFields: struct {
+		ID   int64  `db:"id" dbid:"AUTOINCREMENT"`
+		Name string `db:"name"`
+	}{}}
+	want = "create table auto_test (id bigint generated by default as identity primary key,name text)"
+	stmt, _ = ToCreateTable(&autoTbl, pgDialect)
+	if stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+}
+
+func TestToInsertWithIds(t *testing.T) {
+	want := "insert into test.gen_test (id,name,comment) values ($1,$2,$3)"
+	stmt, err := ToInsertWithIds(&generatorTbl, pgDialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+
+	want = "insert into test.gen_test (id,name,comment) values (:id,:name,:comment)"
+	stmt, _ = ToInsertWithIds(&generatorTbl, oracleDialect)
+	if stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+
+	params := structToIArray(&generatorTest{ID: 7, Name: "seven"}, true)
+	if len(params) != 3 || params[0] != int32(7) || params[1] != "seven" || params[2] != nil {
+		t.Errorf("Got %v want [7 seven <nil>]", params)
+	}
+
+	pg := &RdbmsDataStore{db: &SqlxDb{dialect: pgDialect}}
+	input := InsertInput{Dataset: &generatorTbl, KeepIds: true, ReturnId: true}
+	if _, _, err := pg.insertStmt(input); err == nil {
+		t.Error("Expected an error returning ids for records that keep their ids")
+	}
+}
+
+func TestInsertStmtUpsertReturnId(t *testing.T) {
+	input := InsertInput{Dataset: &generatorTbl, Upsert: true, ConflictColumns: []string{"name"}, ReturnId: true}
+	oracle := &RdbmsDataStore{db: &SqlxDb{dialect: oracleDialect}}
+	if _, _, err := oracle.insertStmt(input); err == nil {
+		t.Error("Expected an error returning ids from an oracle merge")
+	}
+
+	pg := &RdbmsDataStore{db: &SqlxDb{dialect: pgDialect}}
+	want := "insert into test.gen_test (id,name,comment) values (nextval('gen_id_seq'),$1,$2) on conflict (name) do nothing returning id"
+	stmt, _, err := pg.insertStmt(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt != want {
+		t.Errorf("Got %s want %s", stmt, want)
+	}
+}
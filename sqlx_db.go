@@ -1,6 +1,7 @@
 package goquery
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -21,6 +22,9 @@ type SqlxExecResult struct {
 }
 
 func (ser SqlxExecResult) RowsAffected() int64 {
+	if ser.res == nil {
+		return 0
+	}
 	rows, err := ser.res.RowsAffected()
 	if err != nil {
 		log.Println(err)
@@ -59,10 +63,128 @@ func (s *SqlRows) ScanStruct(dest interface{}) error {
 	return s.rowScanner.Scan(dest)
 }
 
+func (s *SqlRows) Err() error {
+	return s.rows.Err()
+}
+
 func (s *SqlRows) Close() error {
 	return s.rows.Close()
 }
 
+type sqlxBatchStmt struct {
+	stmt   string
+	params []interface{}
+}
+
+// SqlxBatch queues statements to be run as prepared statements in a single
+// transaction when the batch is sent.
+type SqlxBatch struct {
+	stmts []sqlxBatchStmt
+}
+
+func (sb *SqlxBatch) Queue(stmt string, params ...interface{}) {
+	sb.stmts = append(sb.stmts, sqlxBatchStmt{stmt, params})
+}
+
+func (sb *SqlxBatch) Len() int {
+	return len(sb.stmts)
+}
+
+// SqlxBatchResult runs each queued statement as its result is read, preparing
+// each distinct statement once.  Statements that are not read are run on Close.
+// If the batch was not sent on a transaction, one is started when the batch is sent
+// and is committed on Close, or rolled back if any statement failed.
+type SqlxBatchResult struct {
+	ctx      context.Context
+	tx       *sqlx.Tx
+	ownsTx   bool
+	stmts    []sqlxBatchStmt
+	prepared map[string]*sqlx.Stmt
+	pos      int
+	err      error
+	closed   bool
+}
+
+func (sbr *SqlxBatchResult) next() (*sqlx.Stmt, []interface{}, error) {
+	if sbr.err != nil {
+		return nil, nil, sbr.err
+	}
+	if sbr.pos >= len(sbr.stmts) {
+		return nil, nil, errors.New("no more results in batch")
+	}
+	bs := sbr.stmts[sbr.pos]
+	sbr.pos++
+	ps, ok := sbr.prepared[bs.stmt]
+	if !ok {
+		var err error
+		ps, err = sbr.tx.PreparexContext(sbr.ctx, bs.stmt)
+		if err != nil {
+			sbr.err = err
+			return nil, nil, err
+		}
+		sbr.prepared[bs.stmt] = ps
+	}
+	return ps, bs.params, nil
+}
+
+func (sbr *SqlxBatchResult) Exec() (ExecResult, error) {
+	ps, params, err := sbr.next()
+	if err != nil {
+		return SqlxExecResult{}, err
+	}
+	res, err := ps.ExecContext(sbr.ctx, params...)
+	if err != nil {
+		sbr.err = err
+	}
+	return SqlxExecResult{res}, err
+}
+
+func (sbr *SqlxBatchResult) QueryRow() Row {
+	ps, params, err := sbr.next()
+	if err != nil {
+		return sqlxBatchRow{nil, sbr, err}
+	}
+	return sqlxBatchRow{ps.QueryRowxContext(sbr.ctx, params...), sbr, nil}
+}
+
+func (sbr *SqlxBatchResult) Close() error {
+	if sbr.closed {
+		return sbr.err
+	}
+	sbr.closed = true
+	for sbr.err == nil && sbr.pos < len(sbr.stmts) {
+		sbr.Exec()
+	}
+	for _, ps := range sbr.prepared {
+		ps.Close()
+	}
+	if sbr.ownsTx && sbr.tx != nil {
+		if sbr.err != nil {
+			sbr.tx.Rollback()
+		} else {
+			sbr.err = sbr.tx.Commit()
+		}
+	}
+	return sbr.err
+}
+
+type sqlxBatchRow struct {
+	row *sqlx.Row
+	sbr *SqlxBatchResult
+	err error
+}
+
+func (r sqlxBatchRow) Scan(dest ...interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+	err := r.row.Scan(dest...)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		r.sbr.err = err
+	}
+	return err
+}
+
 type SqlxDb struct {
 	db      *sqlx.DB
 	dialect DbDialect
@@ -90,7 +212,14 @@ func NewSqlxConnection(config *RdbmsConfig) (SqlxDb, error) {
 	return SqlxDb{con, dialect}, err
 }
 
-func (sdb *SqlxDb) querier(tx *Tx) sqlx.Queryer {
+func (sdb *SqlxDb) querier(tx *Tx) sqlx.QueryerContext {
+	if tx != nil {
+		return tx.SqlXTx()
+	}
+	return sdb.db
+}
+
+func (sdb *SqlxDb) execer(tx *Tx) sqlx.ExecerContext {
 	if tx != nil {
 		return tx.SqlXTx()
 	}
@@ -101,64 +230,154 @@ func (sdb *SqlxDb) Connection() interface{} {
 	return sdb.db
 }
 
-func (sdb *SqlxDb) Select(dest interface{}, tx *Tx, stmt string, params ...interface{}) error {
+func (sdb *SqlxDb) Dialect() DbDialect {
+	return sdb.dialect
+}
+
+func (sdb *SqlxDb) Select(ctx context.Context, dest interface{}, tx *Tx, stmt string, params ...interface{}) error {
 	if len(params) == 0 {
-		return sqlx.Select(sdb.querier(tx), dest, stmt)
+		return sqlx.SelectContext(ctx, sdb.querier(tx), dest, stmt)
 	}
-	return sqlx.Select(sdb.querier(tx), dest, stmt, params...)
+	return sqlx.SelectContext(ctx, sdb.querier(tx), dest, stmt, params...)
 }
 
-func (sdb *SqlxDb) Get(dest interface{}, tx *Tx, stmt string, params ...interface{}) error {
+func (sdb *SqlxDb) Get(ctx context.Context, dest interface{}, tx *Tx, stmt string, params ...interface{}) error {
 	if len(params) == 0 {
-		return sqlx.Get(sdb.querier(tx), dest, stmt)
+		return sqlx.GetContext(ctx, sdb.querier(tx), dest, stmt)
 	}
-	return sqlx.Get(sdb.querier(tx), dest, stmt, params...)
+	return sqlx.GetContext(ctx, sdb.querier(tx), dest, stmt, params...)
 }
 
-func (sdb *SqlxDb) Query(tx *Tx, stmt string, params ...interface{}) (Rows, error) {
-	rows, err := sdb.db.Query(stmt, params...)
+func (sdb *SqlxDb) Query(ctx context.Context, tx *Tx, stmt string, params ...interface{}) (Rows, error) {
+	rows, err := sdb.querier(tx).QueryContext(ctx, stmt, params...)
 	return &SqlRows{rows, nil}, err
 }
 
-func (sdb *SqlxDb) Exec(tx *Tx, stmt string, params ...interface{}) error {
-	_, err := sdb.db.Exec(stmt, params...)
+func (sdb *SqlxDb) Exec(ctx context.Context, tx *Tx, stmt string, params ...interface{}) error {
+	_, err := sdb.execer(tx).ExecContext(ctx, stmt, params...)
 	return err
 }
 
-func (sdb *SqlxDb) Execr(tx *Tx, stmt string, params ...interface{}) (ExecResult, error) {
-	res, err := sdb.db.Exec(stmt, params...)
+func (sdb *SqlxDb) Execr(ctx context.Context, tx *Tx, stmt string, params ...interface{}) (ExecResult, error) {
+	res, err := sdb.execer(tx).ExecContext(ctx, stmt, params...)
 	return SqlxExecResult{res}, err
 }
 
-func (sdb *SqlxDb) MustExec(tx *Tx, stmt string, params ...interface{}) {
-	res := sdb.db.MustExec(stmt, params...)
-	//@TODO what to do with result?
-	fmt.Println(res)
+func (sdb *SqlxDb) MustExec(ctx context.Context, tx *Tx, stmt string, params ...interface{}) {
+	_, err := sdb.execer(tx).ExecContext(ctx, stmt, params...)
+	if err != nil {
+		panic(err)
+	}
 }
 
-func (sdb *SqlxDb) MustExecr(tx *Tx, stmt string, params ...interface{}) ExecResult {
-	res := sdb.db.MustExec(stmt, params...)
+func (sdb *SqlxDb) MustExecr(ctx context.Context, tx *Tx, stmt string, params ...interface{}) ExecResult {
+	res, err := sdb.execer(tx).ExecContext(ctx, stmt, params...)
+	if err != nil {
+		panic(err)
+	}
 	return SqlxExecResult{res}
 }
 
+func (sdb *SqlxDb) CopyFrom(ctx context.Context, ds DataSet, recs reflect.Value, tx *Tx) (int64, error) {
+	return 0, ErrCopyNotSupported
+}
+
 func (sdb *SqlxDb) Batch() (Batch, error) {
-	return nil, errors.New("batch operations are not supported by the sqlx driver")
+	return &SqlxBatch{}, nil
 }
 
-func (sdb *SqlxDb) SendBatch(batch Batch) BatchResult {
-	return nil
+func (sdb *SqlxDb) SendBatch(ctx context.Context, tx *Tx, batch Batch) BatchResult {
+	sbr := SqlxBatchResult{
+		ctx:      ctx,
+		stmts:    batch.(*SqlxBatch).stmts,
+		prepared: make(map[string]*sqlx.Stmt),
+	}
+	if tx != nil {
+		sbr.tx = tx.SqlXTx()
+	} else {
+		sbr.tx, sbr.err = sdb.db.BeginTxx(ctx, nil)
+		sbr.ownsTx = true
+	}
+	return &sbr
 }
 
 func (sdb *SqlxDb) InsertStmt(ds DataSet) (string, error) {
+	if stmt, ok := ds.Commands()[insertkey]; ok {
+		return stmt, nil
+	}
 	return ToInsert(ds, sdb.dialect)
 }
 
-func (sdb *SqlxDb) Insert(ds DataSet, rec interface{}, tx *Tx) error {
-	//pdb.db.Exec(context.Background(),stmt,
-	return nil
+func (sdb *SqlxDb) UpsertStmt(ds DataSet, conflict []string, update []string) (string, error) {
+	return ToUpsert(ds, sdb.dialect, conflict, update)
+}
+
+func (sdb *SqlxDb) Insert(ctx context.Context, stmt string, rec interface{}, tx *Tx) (int64, error) {
+	res, err := sdb.execer(tx).ExecContext(ctx, stmt, StructToIArray(rec)...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// InsertReturning runs an insert statement with a returning clause and sets the returned
+// id on the dbid tagged field of rec, which must be a pointer to a struct.  Dialects that
+// return the id into a bind parameter receive it through a sql.Out parameter.
+func (sdb *SqlxDb) InsertReturning(ctx context.Context, stmt string, rec interface{}, tx *Tx) (int64, error) {
+	id, err := StructIdPointer(rec)
+	if err != nil {
+		return 0, err
+	}
+	params := StructToIArray(rec)
+	if sdb.dialect.ReturningInto {
+		params = append(params, sql.Out{Dest: id})
+		res, err := sdb.execer(tx).ExecContext(ctx, stmt, params...)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	}
+	err = sdb.querier(tx).QueryRowxContext(ctx, stmt, params...).Scan(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func (sdb *SqlxDb) UpdateStmt(ds DataSet) (string, error) {
+	if stmt, ok := ds.Commands()[updatekey]; ok {
+		return stmt, nil
+	}
+	return ToUpdateStmt(ds, sdb.dialect)
+}
+
+func (sdb *SqlxDb) Update(ctx context.Context, ds DataSet, rec interface{}, tx *Tx) (int64, error) {
+	stmt, err := sdb.UpdateStmt(ds)
+	if err != nil {
+		return 0, err
+	}
+	params, err := StructToUpdateIArray(rec)
+	if err != nil {
+		return 0, err
+	}
+	res, err := sdb.execer(tx).ExecContext(ctx, stmt, params...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (sdb *SqlxDb) DeleteStmt(ds DataSet) (string, error) {
+	if stmt, ok := ds.Commands()[deletekey]; ok {
+		return stmt, nil
+	}
+	return ToDelete(ds, sdb.dialect)
 }
 
-func (sdb *SqlxDb) Transaction() (Tx, error) {
-	tx, err := sdb.db.Beginx()
+func (sdb *SqlxDb) Transaction(ctx context.Context) (Tx, error) {
+	tx, err := sdb.db.BeginTxx(ctx, nil)
 	return Tx{tx}, err
 }
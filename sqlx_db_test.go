@@ -1,13 +1,17 @@
 package goquery
 
 import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
 	_ "github.com/jackc/pgx/v4/stdlib"
 )
 
-/*
 func sqlxsetup(t *testing.T) DataStore {
 	store := getSqlxStore(t)
-	err := Transaction(store, func(tx Tx) {
+	err := store.Transaction(func(tx Tx) {
 		sqltx := tx.SqlXTx()
 		sql := `create table fishing_spots(
 			id serial not null primary key,
@@ -31,9 +35,8 @@ func sqlxsetup(t *testing.T) DataStore {
 }
 
 func sqlxteardown(store DataStore, t *testing.T) {
-	err := Transaction(store, func(tx Tx) {
-		sqltx := tx.SqlXTx()
-		sqltx.MustExec("drop table fishing_spots")
+	err := store.Transaction(func(tx Tx) {
+		store.MustExec(&tx, "drop table fishing_spots")
 	})
 	if err != nil {
 		t.Errorf("Failed to teardown test:%s\n", err)
@@ -42,15 +45,26 @@ func sqlxteardown(store DataStore, t *testing.T) {
 
 func getSqlxStore(t *testing.T) DataStore {
 	config := RdbmsConfigFromEnv()
-	dialect, _ := getDialect("pgx")
-	db, err := NewSqlxConnection(config, "pgx")
+	if config.DbDriver == "" {
+		config.DbDriver = "pgx"
+	}
+	db, err := NewSqlxConnection(config)
 	if err != nil {
 		t.Errorf("Failed to connect to store:%s\n", err)
 	}
-	store := SqlDataStore{&db, dialect}
+	store := RdbmsDataStore{&db}
 	return &store
 }
 
+func sqlxCount(store DataStore, t *testing.T) int {
+	var count int
+	err := store.Select("select count(*) from fishing_spots").Dest(&count).Fetch()
+	if err != nil {
+		t.Error(err)
+	}
+	return count
+}
+
 func TestSqlxConnection(t *testing.T) {
 	getSqlxStore(t)
 }
@@ -60,9 +74,9 @@ func TestSqlxJson(t *testing.T) {
 	store := sqlxsetup(t)
 	defer sqlxteardown(store, t)
 
-	json, err := store.Select(nil).
-		Sql("select * from fishing_spots").
+	json, err := store.Select("select * from fishing_spots order by id").
 		OmitNull(false).
+		IsJsonArray(true).
 		FetchJSON()
 	if err != nil {
 		t.Errorf("Failed JSON Test: %s\n", err)
@@ -74,31 +88,22 @@ func TestSqlxJson(t *testing.T) {
 }
 
 func TestSqlxCsv(t *testing.T) {
-	correctResult := `"id","location"
-1,"Alpine Frove"
-2,"Rivertown"
-3,"Pine Island"
-`
+	correctResult := "id,location\n1,Alpine Frove\n2,Rivertown\n3,Pine Island\n4,\n"
 	store := sqlxsetup(t)
 	defer sqlxteardown(store, t)
 
-	csv, err := store.Select(nil).
-		Sql("select * from fishing_spots").
-		FetchCSV()
+	builder := strings.Builder{}
+	err := store.Select("select * from fishing_spots order by id").
+		OutputCsv(&builder).
+		Fetch()
 	if err != nil {
-		t.Errorf("Failed JSON Test: %s\n", err)
+		t.Errorf("Failed CSV Test: %s\n", err)
 	}
-
-	if csv != correctResult {
-		t.Errorf("Failed CSV Test: Got %s want %s", csv, correctResult)
+	if builder.String() != correctResult {
+		t.Errorf("Failed CSV Test: Got %s want %s", builder.String(), correctResult)
 	}
 }
 
-type FishingSpot struct {
-	ID       int32   `db:"id"`
-	Location *string `db:"location"`
-}
-
 func TestSqlxSlice(t *testing.T) {
 	ap := "Alpine Frove"
 	rt := "Rivertown"
@@ -107,43 +112,132 @@ func TestSqlxSlice(t *testing.T) {
 		{1, &ap},
 		{2, &rt},
 		{3, &pi},
+		{4, nil},
 	}
 
 	store := sqlxsetup(t)
 	defer sqlxteardown(store, t)
 
-	///////////autogenerate select///////////////////
-	fsTbl := TableImpl{
-		Name:   "fishing_spots",
-		Fields: FishingSpot{},
+	fsTbl := TableDataSet{
+		Name: "fishing_spots",
+		Statements: Statements{
+			"named-select": `select * from fishing_spots order by id`,
+		},
 	}
 
-	res, err := store.Select(&fsTbl).FetchSlice()
+	dest := []FishingSpot{}
+	err := store.Select().
+		DataSet(&fsTbl).
+		StatementKey("named-select").
+		Dest(&dest).
+		Fetch()
 	if err != nil {
 		t.Errorf("Failed Slice Test:%s\n", err)
 	}
 
-	if reflect.DeepEqual(res, correctResult) {
-		t.Errorf("Failed Slice Test: Got %v want %v", res, correctResult)
+	if !reflect.DeepEqual(dest, correctResult) {
+		t.Errorf("Failed Slice Test: Got %v want %v", dest, correctResult)
 	}
+}
 
-	/////////////////add select statement///////////
-	stmts := map[string]string{
-		"named-select": `select * from fishing_spots`,
+func TestSqlxInsert(t *testing.T) {
+	store := sqlxsetup(t)
+	defer sqlxteardown(store, t)
+
+	l10 := "New Spot 10"
+	l11 := "New Spot 11"
+	fsTbl := TableDataSet{
+		Name:        "fishing_spots",
+		TableFields: FishingSpot{},
 	}
 
-	fsTbl.Statements = stmts
-	res, err = store.Select(&fsTbl).
-		StatementKey("named-select").
-		FetchSlice()
+	err := store.Insert(&fsTbl).Records(FishingSpot{10, &l10}).Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	err = store.Insert(&fsTbl).Records([]FishingSpot{{11, &l11}}).Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if count := sqlxCount(store, t); count != 6 {
+		t.Errorf("Failed Insert Test: Got %d rows want 6", count)
+	}
+}
+
+func TestSqlxRollback(t *testing.T) {
+	store := sqlxsetup(t)
+	defer sqlxteardown(store, t)
 
+	l10 := "New Spot 10"
+	fsTbl := TableDataSet{
+		Name:        "fishing_spots",
+		TableFields: FishingSpot{},
+	}
+
+	tx, err := store.NewTransaction()
 	if err != nil {
-		t.Errorf("Failed Slice Test:%s\n", err)
+		t.Fatal(err)
+	}
+	err = store.Insert(&fsTbl).Records(FishingSpot{10, &l10}).Tx(&tx).Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	store.MustExec(&tx, "delete from fishing_spots where id=$1", 1)
+
+	var count int
+	err = store.Select("select count(*) from fishing_spots").Tx(&tx).Dest(&count).Fetch()
+	if err != nil {
+		t.Error(err)
+	}
+	if count != 4 {
+		t.Errorf("Failed Rollback Test: Got %d rows in transaction want 4", count)
+	}
+
+	err = tx.Rollback()
+	if err != nil {
+		t.Error(err)
+	}
+	dest := []FishingSpot{}
+	err = store.Select("select * from fishing_spots where id in (1,10)").Dest(&dest).Fetch()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(dest) != 1 || dest[0].ID != 1 {
+		t.Errorf("Failed Rollback Test: Got %v want only id 1", dest)
 	}
 
-	if reflect.DeepEqual(res, correctResult) {
-		t.Errorf("Failed Slice Test: Got %v want %v", res, correctResult)
+	err = store.Transaction(func(tx Tx) {
+		store.MustExec(&tx, "delete from fishing_spots")
+		panic("abort")
+	})
+	if err == nil {
+		t.Error("Failed Rollback Test: expected the panic to be returned as an error")
 	}
+	if count := sqlxCount(store, t); count != 4 {
+		t.Errorf("Failed Rollback Test: Got %d rows want 4", count)
+	}
+}
+
+func TestSqlxInsertBatch(t *testing.T) {
+	store := sqlxsetup(t)
+	defer sqlxteardown(store, t)
 
+	count := 250
+	fs := make([]FishingSpot, count)
+	for i := 0; i < count; i++ {
+		val := strconv.Itoa(i)
+		fs[i] = FishingSpot{int32(i + 10), &val}
+	}
+	fsTbl := TableDataSet{
+		Name:        "fishing_spots",
+		TableFields: FishingSpot{},
+	}
+
+	err := store.Insert(&fsTbl).Records(&fs).Batch(true).BatchSize(100).Execute()
+	if err != nil {
+		t.Error(err)
+	}
+	if rows := sqlxCount(store, t); rows != count+4 {
+		t.Errorf("Failed Batch Test: Got %d rows want %d", rows, count+4)
+	}
 }
-*/
@@ -1,6 +1,8 @@
 package goquery
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	//"github.com/ulule/deepcopier"
 )
@@ -87,20 +89,26 @@ func isSlice(data interface{}) bool {
 }
 
 func StructToIArray(data interface{}) []interface{} {
+	return structToIArray(data, false)
+}
+
+// structToIArray returns the db tagged field values of data.  Generated dbid fields
+// are only included when withIds is true.
+func structToIArray(data interface{}, withIds bool) []interface{} {
 	rval := reflect.ValueOf(data)
 	val := reflect.Indirect(rval)
 	if val.Kind() == reflect.Slice {
 		val = val.Elem()
 	}
-	typ := reflect.TypeOf(data)
-	if typ.Kind() == reflect.Ptr {
-		typ = typ.Elem()
+	if !val.IsValid() {
+		return nil
 	}
+	typ := val.Type()
 
 	fieldNum := val.NumField()
 	var ia []interface{}
 	for i := 0; i < fieldNum; i++ {
-		if tagval, ok := typ.Field(i).Tag.Lookup("dbid"); ok {
+		if tagval, ok := typ.Field(i).Tag.Lookup("dbid"); ok && !withIds {
 			if tagval == "AUTOINCREMENT" || tagval == "SEQUENCE" {
 				continue
 			}
@@ -120,13 +128,143 @@ func StructToIArray(data interface{}) []interface{} {
 		//most useful for encapulation
 		if typ.Field(i).Type.Kind() == reflect.Struct {
 			v := val.Field(i)
-			embeddedParams := StructToIArray(reflect.Indirect(v).Interface())
+			embeddedParams := structToIArray(reflect.Indirect(v).Interface(), withIds)
 			ia = append(ia, embeddedParams...)
 		}
 	}
 	return ia
 }
 
+// StructToColumns returns the column names for the values returned by StructToIArray, in the same order.
+func StructToColumns(data interface{}) []string {
+	typ := reflect.TypeOf(data)
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	//records of a []*T slice
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typeToColumns(typ, false)
+}
+
+func typeToColumns(typ reflect.Type, withIds bool) []string {
+	var columns []string
+	for i := 0; i < typ.NumField(); i++ {
+		if tagval, ok := typ.Field(i).Tag.Lookup("dbid"); ok && !withIds {
+			if tagval == "AUTOINCREMENT" || tagval == "SEQUENCE" {
+				continue
+			}
+		}
+		if tagval, ok := typ.Field(i).Tag.Lookup("db"); ok {
+			if tagval != "" && tagval != "-" {
+				columns = append(columns, tagval)
+			}
+		}
+		if typ.Field(i).Type.Kind() == reflect.Struct {
+			columns = append(columns, typeToColumns(typ.Field(i).Type, withIds)...)
+		}
+	}
+	return columns
+}
+
+// dbFieldIndexes maps the db tag of each field of typ, including fields of
+// embedded structs, to the index sequence used by reflect.Value.FieldByIndex.
+func dbFieldIndexes(typ reflect.Type) map[string][]int {
+	indexes := make(map[string][]int)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if tagval, ok := field.Tag.Lookup("db"); ok && isDbField(tagval) {
+			indexes[tagval] = []int{i}
+			continue
+		}
+		if field.Type.Kind() == reflect.Struct {
+			for tag, index := range dbFieldIndexes(field.Type) {
+				indexes[tag] = append([]int{i}, index...)
+			}
+		}
+	}
+	return indexes
+}
+
+// StructToUpdateIArray returns the db tagged values of a struct in the order
+// expected by ToUpdate: all non id fields followed by the dbid tagged field.
+func StructToUpdateIArray(data interface{}) ([]interface{}, error) {
+	val := reflect.Indirect(reflect.ValueOf(data))
+	if !val.IsValid() {
+		return nil, errors.New("invalid record.  expected a struct")
+	}
+	typ := val.Type()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid record type %s.  expected a struct", typ)
+	}
+	fieldNum := typ.NumField()
+	var ia []interface{}
+	var id interface{}
+	hasId := false
+	for i := 0; i < fieldNum; i++ {
+		if tagval, ok := typ.Field(i).Tag.Lookup("db"); ok && isDbField(tagval) {
+			v := fieldValue(val.Field(i))
+			if _, ok := typ.Field(i).Tag.Lookup("dbid"); ok {
+				id = v
+				hasId = true
+				continue
+			}
+			ia = append(ia, v)
+		}
+	}
+	if !hasId {
+		return nil, fmt.Errorf("invalid record type %s.  missing a 'dbid' tagged field", typ)
+	}
+	return append(ia, id), nil
+}
+
+// StructIdValue returns the value of the dbid tagged field of a struct.
+func StructIdValue(data interface{}) (interface{}, error) {
+	val := reflect.Indirect(reflect.ValueOf(data))
+	typ := val.Type()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid record type %s.  expected a struct", typ)
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if _, ok := typ.Field(i).Tag.Lookup("dbid"); ok {
+			return fieldValue(val.Field(i)), nil
+		}
+	}
+	return nil, fmt.Errorf("invalid record type %s.  missing a 'dbid' tagged field", typ)
+}
+
+// StructIdPointer returns a pointer to the dbid tagged field of a struct.  data must be
+// a pointer to a struct so the field can be set.
+func StructIdPointer(data interface{}) (interface{}, error) {
+	rval := reflect.ValueOf(data)
+	if rval.Kind() != reflect.Pointer || rval.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid record type %s.  expected a pointer to a struct", rval.Type())
+	}
+	val := rval.Elem()
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		if _, ok := typ.Field(i).Tag.Lookup("dbid"); ok {
+			return val.Field(i).Addr().Interface(), nil
+		}
+	}
+	return nil, fmt.Errorf("invalid record type %s.  missing a 'dbid' tagged field", typ)
+}
+
+func fieldValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
+	return reflect.Indirect(v).Interface()
+}
+
+func isDbField(tagval string) bool {
+	return tagval != "" && tagval != "-" && tagval != "_"
+}
+
 /*
 func StructToIArray2(data interface{}) []interface{} {
 	rval := reflect.ValueOf(data)
@@ -0,0 +1,54 @@
+package goquery
+
+import (
+	"reflect"
+	"testing"
+)
+
+type TagEmbedded struct {
+	Notes string `db:"notes"`
+}
+
+type tagTest struct {
+	ID    int32  `db:"id" dbid:"SEQUENCE"`
+	Name  string `db:"name"`
+	Skip  string `db:"-"`
+	Plain string
+	TagEmbedded
+}
+
+func TestStructToColumns(t *testing.T) {
+	rec := tagTest{ID: 1, Name: "one", TagEmbedded: TagEmbedded{"note"}}
+	columns := StructToColumns([]tagTest{})
+	values := StructToIArray(rec)
+	if !reflect.DeepEqual(columns, []string{"name", "notes"}) {
+		t.Errorf("Got %v want [name notes]", columns)
+	}
+	if !reflect.DeepEqual(values, []interface{}{"one", "note"}) {
+		t.Errorf("Got %v want [one note]", values)
+	}
+}
+
+func TestStructToColumnsPointerRecords(t *testing.T) {
+	recs := []*tagTest{{ID: 1, Name: "one", TagEmbedded: TagEmbedded{"note"}}}
+	columns := StructToColumns(recs)
+	values := StructToIArray(recs[0])
+	if !reflect.DeepEqual(columns, []string{"name", "notes"}) {
+		t.Errorf("Got %v want [name notes]", columns)
+	}
+	if !reflect.DeepEqual(values, []interface{}{"one", "note"}) {
+		t.Errorf("Got %v want [one note]", values)
+	}
+	if columns := StructToColumns(&recs); !reflect.DeepEqual(columns, []string{"name", "notes"}) {
+		t.Errorf("Got %v want [name notes]", columns)
+	}
+}
+
+func TestStructToUpdateIArrayInvalid(t *testing.T) {
+	var nilRec *tagTest
+	for _, invalid := range []interface{}{nil, nilRec, 1} {
+		if _, err := StructToUpdateIArray(invalid); err == nil {
+			t.Errorf("Expected an error for record %v", invalid)
+		}
+	}
+}